@@ -25,7 +25,7 @@ import (
 )
 
 func main() {
-	err := root.NewRootCommand().Execute()
+	err := root.Execute(root.NewRootCommand())
 	if err != nil {
 		if err.Error() != "subcommand is required" {
 			color.New(color.FgRed).Fprintln(os.Stderr, "FAILED")
@@ -6,6 +6,7 @@ require (
 	github.com/fatih/color v1.7.0
 	github.com/openzipkin/zipkin-go v0.3.0
 	github.com/spf13/cobra v1.2.1
+	golang.org/x/term v0.0.0-20210220032956-6a3ed077a48d
 	gopkg.in/yaml.v2 v2.4.0
 	gotest.tools/v3 v3.0.3
 	k8s.io/api v0.22.3
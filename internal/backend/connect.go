@@ -0,0 +1,183 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"knative.dev/kn-plugin-trace/pkg/config"
+	"knative.dev/kn-plugin-trace/pkg/jaeger"
+	"knative.dev/kn-plugin-trace/pkg/proxy"
+	"knative.dev/kn-plugin-trace/pkg/tempo"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+	"knative.dev/kn-plugin-trace/pkg/zipkin"
+
+	"knative.dev/client/pkg/kn/commands"
+)
+
+// Supported query backends
+const (
+	Auto   = "auto"
+	Zipkin = "zipkin"
+	Jaeger = "jaeger"
+	Tempo  = "tempo"
+)
+
+// Flags selects the backend to query traces from
+type Flags struct {
+	Backend string
+
+	// ZipkinURL overrides the Zipkin endpoint of the tracing configuration
+	ZipkinURL string
+
+	// Options configures how backends are reached
+	Options proxy.Options
+
+	// TempoExporter names the OpenTelemetry collector exporter sending traces to Tempo
+	TempoExporter string
+
+	// RequestTimeout bounds each request to the backend. Zero keeps the timeout of the Kubernetes configuration.
+	RequestTimeout time.Duration
+
+	// Namespaces overrides the installation namespaces of Knative components
+	Namespaces config.Namespaces
+}
+
+// AddFlags adds the backend flags to the given command
+func (f *Flags) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.Backend, "backend", Auto, "backend to query traces from. One of: auto, zipkin, jaeger, tempo")
+	cmd.Flags().StringVar(&f.Options.Transport, "transport", proxy.TransportAuto, "how to reach backends running in the cluster. One of: auto, proxy, port-forward. auto uses the API server service proxy and port-forwards when it is forbidden")
+	cmd.Flags().StringVar(&f.ZipkinURL, "zipkin-url", "", "URL of a Zipkin instance reachable from this machine (e.g. https://zipkin.example.com). Overrides the tracing configuration")
+	cmd.Flags().StringVar(&f.Options.HTTP.CAFile, "zipkin-ca-file", "", "path to a PEM bundle of certificate authorities to trust when connecting to Zipkin over HTTPS")
+	cmd.Flags().BoolVar(&f.Options.HTTP.Insecure, "zipkin-insecure-skip-tls-verify", false, "do not verify the Zipkin server certificate")
+	cmd.Flags().StringVar(&f.Options.HTTP.BearerToken, "zipkin-token", "", "bearer token to authenticate to Zipkin")
+	cmd.Flags().StringVar(&f.Options.HTTP.Username, "zipkin-username", "", "username to authenticate to Zipkin with basic authentication")
+	cmd.Flags().StringVar(&f.Options.HTTP.Password, "zipkin-password", "", "password to authenticate to Zipkin with basic authentication")
+	cmd.Flags().StringVar(&f.TempoExporter, "tempo-exporter", "", "name of the OpenTelemetry collector exporter sending traces to Tempo (e.g. otlp/tempo). Default to the first otlp exporter pointing at a Tempo service")
+	cmd.Flags().DurationVar(&f.RequestTimeout, "request-timeout", 0, "maximum duration of each request to the backend (e.g. 30s). Zero means the timeout of the Kubernetes configuration, if any")
+	AddNamespaceFlags(cmd, &f.Namespaces)
+}
+
+// withTimeout returns the options and Kubernetes configuration honoring the request timeout
+func (f Flags) withTimeout(restcfg *rest.Config) (proxy.Options, *rest.Config) {
+	options := f.Options
+	if restcfg == nil {
+		options.HTTP.Timeout = f.RequestTimeout
+		return options, nil
+	}
+
+	restcfg = rest.CopyConfig(restcfg)
+	if f.RequestTimeout > 0 {
+		restcfg.Timeout = f.RequestTimeout
+	}
+	options.HTTP.Timeout = restcfg.Timeout
+	return options, restcfg
+}
+
+// Connect reads the tracing configuration and connects to the backend storing the traces
+func Connect(ctx context.Context, p *commands.KnParams, flags Flags) (trace.Store, error) {
+	if flags.ZipkinURL != "" {
+		if flags.Backend != Auto && flags.Backend != Zipkin && flags.Backend != "" {
+			return nil, fmt.Errorf("--zipkin-url cannot be used with --backend %s", flags.Backend)
+		}
+
+		options, _ := flags.withTimeout(nil)
+		connection, err := zipkin.ExternalConnect(ctx, flags.ZipkinURL, options.HTTP)
+		if err != nil {
+			return nil, err
+		}
+		return connection, nil
+	}
+
+	restcfg, err := p.RestConfig()
+	if err != nil {
+		return nil, err
+	}
+	_, restcfg = flags.withTimeout(restcfg)
+
+	// Read Tracing configuration
+
+	kubeclient, err := kubernetes.NewForConfig(restcfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Eventing and Serving are expected to send spans to the same backend
+	targets, err := Targets(ctx, p, kubeclient, config.All, flags.Namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := config.LoadZipkin(ctx, kubeclient, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	return ConnectEndpoint(ctx, cfg.ZipkinEndpoint, restcfg, flags)
+}
+
+// ConnectEndpoint connects to the backend storing the traces received on the given Zipkin endpoint.
+// In auto mode, Zipkin is tried first, then Jaeger and Tempo.
+// Backends which cannot be reached release their connections, and the
+// caller must close the returned store.
+func ConnectEndpoint(ctx context.Context, endpoint string, restcfg *rest.Config, flags Flags) (trace.Store, error) {
+	options, restcfg := flags.withTimeout(restcfg)
+
+	switch flags.Backend {
+	case Zipkin:
+		connection, err := zipkin.Connect(ctx, endpoint, restcfg, options)
+		if err != nil {
+			return nil, err
+		}
+		return connection, nil
+	case Jaeger:
+		connection, err := jaeger.Connect(ctx, endpoint, restcfg, options)
+		if err != nil {
+			return nil, err
+		}
+		return connection, nil
+	case Tempo:
+		connection, err := tempo.Connect(ctx, endpoint, restcfg, options, flags.TempoExporter)
+		if err != nil {
+			return nil, err
+		}
+		return connection, nil
+	case Auto, "":
+		connection, zipkinErr := zipkin.Connect(ctx, endpoint, restcfg, options)
+		if zipkinErr == nil {
+			return connection, nil
+		}
+
+		// Jaeger collectors can receive Zipkin spans
+		jaegerConnection, jaegerErr := jaeger.Connect(ctx, endpoint, restcfg, options)
+		if jaegerErr == nil {
+			return jaegerConnection, nil
+		}
+
+		// Tempo usually sits behind an OpenTelemetry collector
+		tempoConnection, tempoErr := tempo.Connect(ctx, endpoint, restcfg, options, flags.TempoExporter)
+		if tempoErr == nil {
+			return tempoConnection, nil
+		}
+		return nil, fmt.Errorf("no backend found for %s: zipkin: %w; jaeger: %v; tempo: %v", endpoint, zipkinErr, jaegerErr, tempoErr)
+	default:
+		return nil, fmt.Errorf("invalid backend %q. Must be one of: auto, zipkin, jaeger, tempo", flags.Backend)
+	}
+}
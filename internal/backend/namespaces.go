@@ -0,0 +1,42 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/kn-plugin-trace/pkg/config"
+
+	"knative.dev/client/pkg/kn/commands"
+)
+
+// AddNamespaceFlags adds the flags overriding the installation namespaces of Knative components
+func AddNamespaceFlags(cmd *cobra.Command, namespaces *config.Namespaces) {
+	cmd.Flags().StringVar(&namespaces.Eventing, "eventing-namespace", "", "namespace where Knative Eventing is installed. Discovered when not set")
+	cmd.Flags().StringVar(&namespaces.Serving, "serving-namespace", "", "namespace where Knative Serving is installed. Discovered when not set")
+}
+
+// Targets returns the tracing ConfigMaps of the given component, discovering
+// the installation namespaces which are not overridden
+func Targets(ctx context.Context, p *commands.KnParams, client kubernetes.Interface, component string, namespaces config.Namespaces) ([]config.Target, error) {
+	var dyn dynamic.Interface
+	if client, err := p.NewDynamicClient(""); err == nil {
+		dyn = client.RawClient()
+	}
+	return config.Targets(component, config.Discover(ctx, client, dyn, namespaces))
+}
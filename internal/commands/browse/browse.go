@@ -0,0 +1,182 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package browse
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"knative.dev/kn-plugin-trace/internal/backend"
+	"knative.dev/kn-plugin-trace/internal/poller"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+
+	"knative.dev/client/pkg/kn/commands"
+)
+
+// resizeInterval is how often the terminal size is checked
+const resizeInterval = 250 * time.Millisecond
+
+type browseFlags struct {
+	all bool
+
+	services  []string
+	source    string
+	eventType string
+	since     time.Duration
+	overlap   time.Duration
+	delay     time.Duration
+	interval  time.Duration
+	limit     int
+
+	backendFlags backend.Flags
+}
+
+func (c *browseFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&c.all, "all", "a", false, "show non-cloudevents traces")
+	cmd.Flags().StringSliceVar(&c.services, "service", nil, "only show traces going through this service. Can be repeated")
+	cmd.Flags().StringVar(&c.source, "source", "", "only show traces of CloudEvents with this source")
+	cmd.Flags().StringVar(&c.eventType, "type", "", "only show traces of CloudEvents with this type")
+	cmd.Flags().DurationVar(&c.since, "since", 15*time.Minute, "initially show traces more recent than this duration")
+	cmd.Flags().IntVar(&c.limit, "limit", 200, "maximum number of traces to fetch per service and poll")
+	cmd.Flags().DurationVar(&c.interval, "interval", time.Second, "how long to wait between polls")
+	cmd.Flags().DurationVar(&c.overlap, "overlap", 30*time.Second, "how far back each poll searches before the previous one ended, to catch spans reported late")
+	cmd.Flags().DurationVar(&c.delay, "delay", 0, "how long to wait before searching for spans, to let them be reported and absorb clock skew with the cluster")
+	c.backendFlags.AddFlags(cmd)
+}
+
+func (c *browseFlags) validate() error {
+	if c.since <= 0 || c.interval <= 0 || c.limit <= 0 {
+		return errors.New("--since, --interval and --limit must be positive")
+	}
+	if c.overlap < 0 || c.delay < 0 {
+		return errors.New("--overlap and --delay must not be negative")
+	}
+	return nil
+}
+
+// query returns the trace search matching the flags, without time window
+func (c *browseFlags) query() trace.Query {
+	tags := make(map[string]string)
+	if c.source != "" {
+		tags[trace.CloudEventSourceTag] = c.source
+	}
+	if c.eventType != "" {
+		tags[trace.CloudEventTypeTag] = c.eventType
+	}
+	return trace.Query{Tags: tags, Limit: c.limit}
+}
+
+// NewBrowseCommand implements 'kn trace browse' command
+func NewBrowseCommand(p *commands.KnParams) *cobra.Command {
+	var browseflags browseFlags
+
+	cmd := &cobra.Command{
+		Use:   "browse",
+		Short: "Browse traces interactively",
+		Long:  "Browse traces in a full-screen terminal interface, updated as spans are reported",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := browseflags.validate(); err != nil {
+				return err
+			}
+
+			store, err := backend.Connect(cmd.Context(), p, browseflags.backendFlags)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			scr, err := openScreen(os.Stdin, os.Stdout)
+			if err != nil {
+				return err
+			}
+			defer scr.close()
+
+			return run(cmd.Context(), scr, store, browseflags)
+		},
+	}
+
+	browseflags.addFlags(cmd)
+
+	return cmd
+}
+
+// pollResult is the outcome of a poll
+type pollResult struct {
+	spans []trace.Span
+	err   error
+}
+
+// run displays the browser until the user quits or the context is done
+func run(ctx context.Context, scr *screen, store trace.Store, flags browseFlags) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan pollResult)
+	go poll(ctx, poller.New(store, flags.services, poller.LateShow, flags.overlap), flags, results)
+
+	keys := make(chan key)
+	go readKeys(ctx, scr.in, keys)
+
+	ticker := time.NewTicker(resizeInterval)
+	defer ticker.Stop()
+
+	m := newModel(flags.all)
+	m.status = "loading..."
+	var width, height int
+	dirty := true
+	for {
+		if w, h := scr.size(); dirty || w != width || h != height {
+			width, height = w, h
+			if err := scr.draw(m.render(width, height)); err != nil {
+				return err
+			}
+		}
+		dirty = true
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case k := <-keys:
+			m.handle(k, height-3)
+			if m.quit {
+				return nil
+			}
+		case r := <-results:
+			m.add(r.spans)
+			m.setError(r.err, time.Now())
+		case <-ticker.C:
+			dirty = false
+		}
+	}
+}
+
+// poll sends the spans reported since the start of the browser window, like 'show --follow'
+func poll(ctx context.Context, spanPoller *poller.Poller, flags browseFlags, results chan<- pollResult) {
+	until := time.Now().Add(-flags.delay)
+	since := until.Add(-flags.since)
+	schedule := poller.Schedule{Interval: flags.interval, Delay: flags.delay}
+
+	spanPoller.Follow(ctx, flags.query(), since, until, schedule, func(r poller.Result) bool {
+		select {
+		case results <- pollResult{spans: r.Spans, err: r.Err}:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	})
+}
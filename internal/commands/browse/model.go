@@ -0,0 +1,304 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package browse
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+// maxTraces is the maximum number of traces kept in the browser. The oldest ones are dropped first.
+const maxTraces = 1000
+
+// entry is a trace of the list
+type entry struct {
+	spans []trace.Span
+	tree  *trace.Tree
+
+	// nodes are the spans of the tree, depth first
+	nodes  []*trace.Node
+	depths []int
+
+	// Attributes of the first CloudEvent of the trace
+	source    string
+	eventType string
+	eventID   string
+
+	failed bool
+}
+
+func (e *entry) add(spans ...trace.Span) {
+	e.spans = append(e.spans, spans...)
+	e.tree = trace.Build(e.spans)[0]
+
+	e.nodes, e.depths = nil, nil
+	e.failed = false
+	e.eventID = ""
+	e.tree.Walk(func(node *trace.Node, depth int) {
+		e.nodes = append(e.nodes, node)
+		e.depths = append(e.depths, depth)
+
+		span := node.Span
+		e.failed = e.failed || span.Failed()
+		if id, ok := span.Tags[trace.CloudEventIDTag]; ok && e.eventID == "" {
+			e.eventID = id
+			e.source = span.Tags[trace.CloudEventSourceTag]
+			e.eventType = span.Tags[trace.CloudEventTypeTag]
+		}
+	})
+}
+
+// matches returns true when the filter is found in the event attributes, services or span names
+func (e *entry) matches(filter string) bool {
+	if filter == "" {
+		return true
+	}
+	for _, s := range []string{e.tree.TraceID, e.source, e.eventType, e.eventID} {
+		if strings.Contains(s, filter) {
+			return true
+		}
+	}
+	for _, node := range e.nodes {
+		if strings.Contains(node.Span.Service(), filter) || strings.Contains(node.Span.Name, filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// pane is the part of the screen receiving keys
+type pane int
+
+const (
+	tracesPane pane = iota
+	spansPane
+)
+
+// model is the state of the browser, independent of the terminal
+type model struct {
+	// all shows traces without CloudEvents
+	all bool
+
+	byID    map[string]*entry
+	entries []*entry // newest first
+
+	// visible are the entries matching the filter
+	visible []*entry
+
+	filter    string
+	filtering bool // true while the filter is being typed
+
+	focus   pane
+	trace   int // index of the selected trace in visible
+	span    int // index of the selected span in the selected trace
+	inspect bool
+
+	// listTop and treeTop are the first lines displayed in each pane
+	listTop int
+	treeTop int
+
+	status string
+	err    error
+	quit   bool
+}
+
+func newModel(all bool) *model {
+	return &model{all: all, byID: make(map[string]*entry)}
+}
+
+// add merges newly fetched spans into their traces
+func (m *model) add(spans []trace.Span) {
+	if len(spans) == 0 {
+		return
+	}
+
+	selected := m.selected()
+
+	byTrace := make(map[string][]trace.Span)
+	for _, span := range spans {
+		byTrace[span.TraceID] = append(byTrace[span.TraceID], span)
+	}
+	for traceID, s := range byTrace {
+		e, ok := m.byID[traceID]
+		if !ok {
+			e = &entry{}
+			m.byID[traceID] = e
+			m.entries = append(m.entries, e)
+		}
+		e.add(s...)
+	}
+
+	sort.SliceStable(m.entries, func(i, j int) bool {
+		return m.entries[i].tree.Start.After(m.entries[j].tree.Start)
+	})
+	for len(m.entries) > maxTraces {
+		oldest := m.entries[len(m.entries)-1]
+		delete(m.byID, oldest.tree.TraceID)
+		m.entries = m.entries[:len(m.entries)-1]
+	}
+
+	m.refresh(selected)
+}
+
+// refresh recomputes the visible entries and keeps the given entry selected when still visible
+func (m *model) refresh(selected *entry) {
+	m.visible = m.visible[:0]
+	for _, e := range m.entries {
+		if (m.all || e.eventID != "") && e.matches(m.filter) {
+			m.visible = append(m.visible, e)
+		}
+	}
+
+	m.trace = 0
+	for i, e := range m.visible {
+		if e == selected {
+			m.trace = i
+			return
+		}
+	}
+	m.span = 0
+	m.treeTop = 0
+}
+
+// selected returns the selected trace, if any
+func (m *model) selected() *entry {
+	if m.trace < len(m.visible) {
+		return m.visible[m.trace]
+	}
+	return nil
+}
+
+// selectedSpan returns the selected span, if any
+func (m *model) selectedSpan() *trace.Node {
+	e := m.selected()
+	if e == nil || m.span >= len(e.nodes) {
+		return nil
+	}
+	return e.nodes[m.span]
+}
+
+// setError records the outcome of the last poll
+func (m *model) setError(err error, now time.Time) {
+	m.err = err
+	if err == nil {
+		m.status = "updated " + now.Format("15:04:05")
+	}
+}
+
+// move moves the selection of the focused pane by delta lines
+func (m *model) move(delta int) {
+	switch m.focus {
+	case tracesPane:
+		m.trace = clamp(m.trace+delta, 0, len(m.visible)-1)
+		m.span = 0
+		m.treeTop = 0
+	case spansPane:
+		if e := m.selected(); e != nil {
+			m.span = clamp(m.span+delta, 0, len(e.nodes)-1)
+		}
+	}
+}
+
+// handle updates the model according to the given key
+func (m *model) handle(k key, pageSize int) {
+	if m.filtering {
+		switch k.code {
+		case keyEnter:
+			m.filtering = false
+		case keyEscape:
+			m.filtering = false
+			m.filter = ""
+		case keyBackspace:
+			if len(m.filter) > 0 {
+				runes := []rune(m.filter)
+				m.filter = string(runes[:len(runes)-1])
+			}
+		case keyRune:
+			m.filter += string(k.r)
+		default:
+			return
+		}
+		m.refresh(m.selected())
+		return
+	}
+
+	switch k.code {
+	case keyUp:
+		m.move(-1)
+	case keyDown:
+		m.move(1)
+	case keyPageUp:
+		m.move(-pageSize)
+	case keyPageDown:
+		m.move(pageSize)
+	case keyHome:
+		m.move(-maxTraces * 1000)
+	case keyEnd:
+		m.move(maxTraces * 1000)
+	case keyTab:
+		if m.focus == tracesPane {
+			m.focus = spansPane
+		} else {
+			m.focus = tracesPane
+		}
+	case keyEnter:
+		if m.focus == tracesPane {
+			m.focus = spansPane
+		} else {
+			m.inspect = !m.inspect
+		}
+	case keyEscape:
+		if m.inspect {
+			m.inspect = false
+		} else if m.focus == spansPane {
+			m.focus = tracesPane
+		} else if m.filter != "" {
+			m.filter = ""
+			m.refresh(m.selected())
+		}
+	case keyCtrlC:
+		m.quit = true
+	case keyRune:
+		switch k.r {
+		case 'q':
+			m.quit = true
+		case 'k':
+			m.move(-1)
+		case 'j':
+			m.move(1)
+		case 'g':
+			m.move(-maxTraces * 1000)
+		case 'G':
+			m.move(maxTraces * 1000)
+		case 't':
+			m.inspect = !m.inspect
+		case '/':
+			m.filtering = true
+		}
+	}
+}
+
+func clamp(v, min, max int) int {
+	if v > max {
+		v = max
+	}
+	if v < min {
+		v = min
+	}
+	return v
+}
@@ -0,0 +1,111 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package browse
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+var now = time.Date(2021, 11, 4, 10, 0, 0, 0, time.UTC)
+
+func event(traceID, id, parentID, eventType string, offset time.Duration) trace.Span {
+	return trace.Span{
+		TraceID:       traceID,
+		ID:            id,
+		ParentID:      parentID,
+		Name:          "send",
+		Timestamp:     now.Add(offset),
+		Duration:      time.Millisecond,
+		LocalEndpoint: &trace.Endpoint{ServiceName: "broker-ingress"},
+		Tags: map[string]string{
+			trace.CloudEventIDTag:     traceID + "-" + id,
+			trace.CloudEventSourceTag: "/demo",
+			trace.CloudEventTypeTag:   eventType,
+		},
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	keys := parseKeys([]byte("q\x1b[A\x1b[6~\r\x1b\x7fé"))
+	expected := []key{
+		{code: keyRune, r: 'q'},
+		{code: keyUp},
+		{code: keyPageDown},
+		{code: keyEnter},
+		{code: keyEscape},
+		{code: keyBackspace},
+		{code: keyRune, r: 'é'},
+	}
+	assert.Equal(t, len(keys), len(expected))
+	for i := range expected {
+		assert.Equal(t, keys[i], expected[i])
+	}
+
+	keys = parseKeys([]byte{0x1b})
+	assert.Equal(t, len(keys), 1)
+	assert.Equal(t, keys[0], key{code: keyEscape})
+}
+
+func TestModel(t *testing.T) {
+	m := newModel(false)
+	m.add([]trace.Span{
+		event("1", "a", "", "dev.knative.ping", 0),
+		event("2", "b", "", "dev.knative.order", time.Second),
+		{TraceID: "3", ID: "c", Name: "GET", Timestamp: now.Add(2 * time.Second)},
+	})
+
+	// Newest first, without traces of other requests
+	assert.Equal(t, len(m.visible), 2)
+	assert.Equal(t, m.selected().eventType, "dev.knative.order")
+
+	// Late spans are merged into their trace and the selection is kept
+	m.handle(key{code: keyDown}, 10)
+	m.add([]trace.Span{event("1", "d", "a", "dev.knative.ping", time.Millisecond)})
+	assert.Equal(t, m.selected().tree.TraceID, "1")
+	assert.Equal(t, len(m.selected().nodes), 2)
+
+	m.handle(key{code: keyTab}, 10)
+	m.handle(key{code: keyDown}, 10)
+	assert.Equal(t, m.selectedSpan().Span.ID, "d")
+
+	// Filter
+	m.handle(key{code: keyRune, r: '/'}, 10)
+	for _, r := range "order" {
+		m.handle(key{code: keyRune, r: r}, 10)
+	}
+	m.handle(key{code: keyEnter}, 10)
+	assert.Equal(t, len(m.visible), 1)
+	assert.Equal(t, m.selected().tree.TraceID, "2")
+
+	m.handle(key{code: keyRune, r: 'q'}, 10)
+	assert.Assert(t, m.quit)
+}
+
+func TestRender(t *testing.T) {
+	m := newModel(true)
+	m.add([]trace.Span{event("1", "a", "", "dev.knative.ping", 0)})
+	m.inspect = true
+
+	lines := m.render(120, 20)
+	assert.Equal(t, len(lines), 20)
+	assert.Assert(t, strings.Contains(lines[0], "1/1 traces"))
+	assert.Assert(t, strings.Contains(lines[1], "dev.knative.ping /demo 1-a"))
+	assert.Assert(t, strings.Contains(strings.Join(lines, "\n"), "cloudevents.type=dev.knative.ping"))
+}
@@ -0,0 +1,208 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package browse
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// keyCode identifies the keys the browser reacts to
+type keyCode int
+
+const (
+	keyRune keyCode = iota
+	keyUp
+	keyDown
+	keyPageUp
+	keyPageDown
+	keyHome
+	keyEnd
+	keyEnter
+	keyTab
+	keyEscape
+	keyBackspace
+	keyCtrlC
+	keyUnknown
+)
+
+// key is a key press. r is only set for keyRune.
+type key struct {
+	code keyCode
+	r    rune
+}
+
+// escapeSequences maps the ANSI sequences of special keys
+var escapeSequences = map[string]keyCode{
+	"\x1b[A":  keyUp,
+	"\x1bOA":  keyUp,
+	"\x1b[B":  keyDown,
+	"\x1bOB":  keyDown,
+	"\x1b[5~": keyPageUp,
+	"\x1b[6~": keyPageDown,
+	"\x1b[H":  keyHome,
+	"\x1b[1~": keyHome,
+	"\x1bOH":  keyHome,
+	"\x1b[F":  keyEnd,
+	"\x1b[4~": keyEnd,
+	"\x1bOF":  keyEnd,
+}
+
+// parseKeys decodes the keys read from a terminal in raw mode.
+// An escape byte which does not start a sequence is the escape key.
+func parseKeys(b []byte) []key {
+	var keys []key
+	for len(b) > 0 {
+		switch b[0] {
+		case 0x1b:
+			n := sequenceLength(b)
+			code, ok := escapeSequences[string(b[:n])]
+			if n == 1 {
+				code = keyEscape
+			} else if !ok {
+				code = keyUnknown
+			}
+			keys = append(keys, key{code: code})
+			b = b[n:]
+			continue
+		case '\r', '\n':
+			keys = append(keys, key{code: keyEnter})
+		case '\t':
+			keys = append(keys, key{code: keyTab})
+		case 0x7f, 0x08:
+			keys = append(keys, key{code: keyBackspace})
+		case 0x03:
+			keys = append(keys, key{code: keyCtrlC})
+		default:
+			r, size := utf8.DecodeRune(b)
+			if r < 0x20 {
+				keys = append(keys, key{code: keyUnknown})
+			} else {
+				keys = append(keys, key{code: keyRune, r: r})
+			}
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// sequenceLength returns the length of the escape sequence at the start of b:
+// ESC [ parameters final-byte, or ESC O final-byte.
+func sequenceLength(b []byte) int {
+	if len(b) < 3 || (b[1] != '[' && b[1] != 'O') {
+		return 1
+	}
+	if b[1] == 'O' {
+		return 3
+	}
+	for i := 2; i < len(b); i++ {
+		if b[i] >= 0x40 && b[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return len(b)
+}
+
+// screen is a terminal switched to raw mode and to the alternate screen
+type screen struct {
+	in  *os.File
+	out *bufio.Writer
+
+	state *term.State
+}
+
+func openScreen(in, out *os.File) (*screen, error) {
+	if !term.IsTerminal(int(in.Fd())) || !term.IsTerminal(int(out.Fd())) {
+		return nil, fmt.Errorf("browse requires an interactive terminal")
+	}
+
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return nil, err
+	}
+
+	s := &screen{in: in, out: bufio.NewWriter(out), state: state}
+
+	// Alternate screen, hidden cursor
+	s.out.WriteString("\x1b[?1049h\x1b[?25l")
+	return s, s.out.Flush()
+}
+
+// close restores the terminal as it was before opening the screen
+func (s *screen) close() {
+	s.out.WriteString("\x1b[?25h\x1b[?1049l")
+	s.out.Flush()
+	term.Restore(int(s.in.Fd()), s.state)
+}
+
+// size returns the width and height of the terminal
+func (s *screen) size() (int, int) {
+	width, height, err := term.GetSize(int(s.in.Fd()))
+	if err != nil {
+		return 80, 24
+	}
+	return width, height
+}
+
+// draw replaces the content of the screen with the given lines
+func (s *screen) draw(lines []string) error {
+	s.out.WriteString("\x1b[H")
+	for i, line := range lines {
+		if i > 0 {
+			s.out.WriteString("\r\n")
+		}
+		s.out.WriteString(line)
+		s.out.WriteString("\x1b[K")
+	}
+	s.out.WriteString("\x1b[J")
+	return s.out.Flush()
+}
+
+// readKeys sends the keys read from in until it fails or the context is done
+func readKeys(ctx context.Context, in io.Reader, keys chan<- key) {
+	buf := make([]byte, 64)
+	for {
+		n, err := in.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, k := range parseKeys(buf[:n]) {
+			select {
+			case keys <- k:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// sanitize removes control characters, which would corrupt the screen
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return ' '
+		}
+		return r
+	}, s)
+}
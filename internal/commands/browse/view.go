@@ -0,0 +1,256 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package browse
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"knative.dev/kn-plugin-trace/internal/output"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+var (
+	reverse = color.New(color.ReverseVideo).SprintFunc()
+	bold    = color.New(color.Bold).SprintFunc()
+	faint   = color.New(color.Faint).SprintFunc()
+	red     = color.New(color.FgRed).SprintFunc()
+)
+
+// help lists the keys, displayed in the status line
+const help = "↑/↓ move  tab switch pane  enter select/inspect  / filter  t tags  q quit"
+
+// render returns the lines of the screen, given its size
+func (m *model) render(width, height int) []string {
+	if width < 40 || height < 5 {
+		return []string{"terminal too small"}
+	}
+
+	lines := make([]string, 0, height)
+
+	title := fmt.Sprintf(" kn trace browse  %d/%d traces", len(m.visible), len(m.entries))
+	if m.filter != "" || m.filtering {
+		title += "  filter: " + m.filter
+	}
+	lines = append(lines, reverse(pad(title, width)))
+
+	bodyHeight := height - 2
+	leftWidth := width * 2 / 5
+	rightWidth := width - leftWidth - 1
+
+	left := m.renderTraces(leftWidth, bodyHeight)
+	right := m.renderSpans(rightWidth, bodyHeight)
+	for i := 0; i < bodyHeight; i++ {
+		lines = append(lines, left[i]+faint("│")+right[i])
+	}
+
+	switch {
+	case m.filtering:
+		lines = append(lines, pad("/"+m.filter+"█", width))
+	case m.err != nil:
+		lines = append(lines, red(truncate("error: "+m.err.Error(), width)))
+	default:
+		status := help
+		if m.status != "" {
+			status = m.status + "  " + help
+		}
+		lines = append(lines, faint(truncate(status, width)))
+	}
+	return lines
+}
+
+// renderTraces returns the lines of the traces pane
+func (m *model) renderTraces(width, height int) []string {
+	m.listTop = scroll(m.listTop, m.trace, height)
+
+	lines := make([]string, 0, height)
+	for i := m.listTop; i < len(m.visible) && len(lines) < height; i++ {
+		e := m.visible[i]
+
+		marker := " "
+		if e.failed {
+			marker = "✗"
+		}
+		label := e.eventType + " " + e.source + " " + e.eventID
+		if e.eventID == "" {
+			label = e.tree.TraceID
+			if len(e.nodes) > 0 {
+				label = e.nodes[0].Span.Service() + " " + e.nodes[0].Span.Name
+			}
+		}
+		line := pad(truncate(sanitize(fmt.Sprintf("%s %8s %s", marker, output.FormatDuration(e.tree.Duration), label)), width), width)
+
+		switch {
+		case i == m.trace && m.focus == tracesPane:
+			line = reverse(line)
+		case i == m.trace:
+			line = bold(line)
+		case e.failed:
+			line = red(line)
+		}
+		lines = append(lines, line)
+	}
+
+	if len(m.visible) == 0 {
+		msg := "waiting for traces..."
+		if len(m.entries) > 0 {
+			msg = "no trace matches the filter"
+		}
+		lines = append(lines, faint(pad(msg, width)))
+	}
+	return fill(lines, width, height)
+}
+
+// renderSpans returns the lines of the span tree of the selected trace,
+// followed by the tags of the selected span when inspecting.
+func (m *model) renderSpans(width, height int) []string {
+	e := m.selected()
+	if e == nil {
+		return fill(nil, width, height)
+	}
+
+	treeHeight := height - 1
+	var tags []string
+	if m.inspect {
+		tags = spanTags(m.selectedSpan())
+		treeHeight = height / 2
+	}
+
+	lines := []string{bold(pad(truncate(fmt.Sprintf(" trace %s %s", e.tree.TraceID, output.FormatDuration(e.tree.Duration)), width), width))}
+
+	m.treeTop = scroll(m.treeTop, m.span, treeHeight)
+	tree := treeLines(e)
+	for i := m.treeTop; i < len(tree) && len(lines) < treeHeight+1; i++ {
+		span := e.nodes[i].Span
+		line := pad(truncate(sanitize(tree[i]), width), width)
+
+		switch {
+		case i == m.span && m.focus == spansPane:
+			line = reverse(line)
+		case span.Failed():
+			line = red(line)
+		}
+		lines = append(lines, line)
+	}
+
+	if m.inspect {
+		lines = fill(lines, width, treeHeight+1)
+		lines = append(lines, faint(pad(truncate("─ tags "+strings.Repeat("─", width), width), width)))
+		for _, tag := range tags {
+			if len(lines) == height {
+				break
+			}
+			lines = append(lines, pad(truncate(sanitize(tag), width), width))
+		}
+	}
+	return fill(lines, width, height)
+}
+
+// treeLines returns one line per span of the trace, in the order of entry.nodes.
+// Lines are colored as a whole so that truncation does not cut escape sequences.
+func treeLines(e *entry) []string {
+	var lines []string
+	var walk func(nodes []*trace.Node, prefix string)
+	walk = func(nodes []*trace.Node, prefix string) {
+		for i, node := range nodes {
+			branch, indent := "├── ", "│   "
+			if i == len(nodes)-1 {
+				branch, indent = "└── ", "    "
+			}
+
+			span := node.Span
+			line := prefix + branch
+			if service := span.Service(); service != "" {
+				line += service + " "
+			}
+			line += span.Name + " +" + output.FormatDuration(e.tree.Offset(span)) + " " + output.FormatDuration(span.Duration)
+			lines = append(lines, line)
+
+			walk(node.Children, prefix+indent)
+		}
+	}
+	walk(e.tree.Roots, " ")
+	return lines
+}
+
+// spanTags returns the description of the span followed by its tags
+func spanTags(node *trace.Node) []string {
+	if node == nil {
+		return nil
+	}
+	span := node.Span
+
+	lines := []string{
+		" id: " + span.ID,
+		" kind: " + string(span.Kind),
+		" service: " + span.Service(),
+	}
+	if remote := span.RemoteService(); remote != "" {
+		lines = append(lines, " remote: "+remote)
+	}
+
+	keys := make([]string, 0, len(span.Tags))
+	for key := range span.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		lines = append(lines, " "+key+"="+span.Tags[key])
+	}
+	for _, annotation := range span.Annotations {
+		lines = append(lines, " @"+annotation.Timestamp.Format("15:04:05.000000")+" "+annotation.Value)
+	}
+	return lines
+}
+
+// scroll returns the first line to display so that the selected line is visible
+func scroll(top, selected, height int) int {
+	if selected < top {
+		return selected
+	}
+	if selected >= top+height {
+		return selected - height + 1
+	}
+	return top
+}
+
+// fill pads lines with blank lines up to height
+func fill(lines []string, width, height int) []string {
+	for len(lines) < height {
+		lines = append(lines, strings.Repeat(" ", width))
+	}
+	return lines
+}
+
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width <= 1 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+func pad(s string, width int) string {
+	if n := len([]rune(s)); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
@@ -0,0 +1,45 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/kn-plugin-trace/internal/backend"
+	"knative.dev/kn-plugin-trace/pkg/config"
+
+	"knative.dev/client/pkg/kn/commands"
+)
+
+// addComponentFlag adds the flag selecting the Knative components to configure
+func addComponentFlag(cmd *cobra.Command, component *string) {
+	cmd.Flags().StringVar(component, "component", config.All, "Knative component whose tracing configuration is used. One of: eventing, serving, all")
+}
+
+// targets returns the tracing ConfigMaps of the given component. Components
+// which are not installed are skipped when all components are selected.
+func targets(ctx context.Context, p *commands.KnParams, client kubernetes.Interface, component string, namespaces config.Namespaces) ([]config.Target, error) {
+	targets, err := backend.Targets(ctx, p, client, component, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	if component != config.All {
+		return targets, nil
+	}
+	return config.Installed(ctx, client, targets)
+}
@@ -28,6 +28,7 @@ func NewConfigCommand(p *commands.KnParams) *cobra.Command {
 	}
 
 	configCmd.AddCommand(NewEnableCommand(p))
+	configCmd.AddCommand(NewDisableCommand(p))
 	configCmd.AddCommand(NewUpdateCommand(p))
 	configCmd.AddCommand(NewViewCommand(p))
 
@@ -0,0 +1,90 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/kn-plugin-trace/internal/backend"
+	"knative.dev/kn-plugin-trace/pkg/config"
+	"knative.dev/kn-plugin-trace/pkg/setup"
+
+	"knative.dev/client/pkg/kn/commands"
+)
+
+type configDisableFlags struct {
+	restore    bool
+	uninstall  bool
+	component  string
+	namespaces config.Namespaces
+}
+
+func (c *configDisableFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&c.restore, "restore", false, "restore debug and sample-rate as they were before tracing was enabled by kn trace")
+	cmd.Flags().BoolVar(&c.uninstall, "uninstall", false, "remove the Zipkin installed by kn trace. Objects not created by kn trace are never removed")
+	addComponentFlag(cmd, &c.component)
+	backend.AddNamespaceFlags(cmd, &c.namespaces)
+}
+
+// NewDisableCommand implements 'kn trace config disable' command
+func NewDisableCommand(p *commands.KnParams) *cobra.Command {
+	var disableFlags configDisableFlags
+
+	cmd := &cobra.Command{
+		Use:   "disable",
+		Short: "Disable tracing",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			restcfg, err := p.RestConfig()
+			if err != nil {
+				return err
+			}
+
+			kubeclient, err := kubernetes.NewForConfig(restcfg)
+			if err != nil {
+				return err
+			}
+
+			selected, err := targets(cmd.Context(), p, kubeclient, disableFlags.component, disableFlags.namespaces)
+			if err != nil {
+				return err
+			}
+
+			if err := setup.Disable(cmd.Context(), kubeclient, selected, disableFlags.restore); err != nil {
+				return fmt.Errorf("failed to disable tracing: %w", err)
+			}
+
+			if !disableFlags.uninstall {
+				return nil
+			}
+
+			// Components which are not disabled may still use Zipkin
+			installed, err := targets(cmd.Context(), p, kubeclient, config.All, disableFlags.namespaces)
+			if err != nil {
+				return err
+			}
+
+			if err := setup.UninstallZipkin(cmd.Context(), kubeclient, installed); err != nil {
+				return fmt.Errorf("failed to uninstall Zipkin: %w", err)
+			}
+			return nil
+		},
+	}
+
+	disableFlags.addFlags(cmd)
+
+	return cmd
+}
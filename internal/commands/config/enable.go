@@ -18,7 +18,9 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/kubernetes"
+	"knative.dev/kn-plugin-trace/internal/backend"
 	"knative.dev/kn-plugin-trace/internal/output"
 	"knative.dev/kn-plugin-trace/pkg/config"
 	"knative.dev/kn-plugin-trace/pkg/setup"
@@ -27,12 +29,16 @@ import (
 )
 
 type configEnableFlags struct {
-	template string
+	template   string
+	component  string
+	namespaces config.Namespaces
 }
 
 func (c *configEnableFlags) addFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&c.template, "template", "t", "zipkin", "tracing configuration template")
 	cobra.MarkFlagRequired(cmd.Flags(), "template")
+	addComponentFlag(cmd, &c.component)
+	backend.AddNamespaceFlags(cmd, &c.namespaces)
 }
 
 // NewEnableCommand implements 'kn trace config enable' command
@@ -54,15 +60,26 @@ func NewEnableCommand(p *commands.KnParams) *cobra.Command {
 				return err
 			}
 
-			cfg, err := config.Load(cmd.Context(), kubeclient)
+			targets, err := targets(cmd.Context(), p, kubeclient, enableFlags.component, enableFlags.namespaces)
 			if err != nil {
 				return err
 			}
 
-			if cfg.Backend == "" || cfg.Backend == "none" {
+			enabled := true
+			for _, target := range targets {
+				cfg, err := config.Load(cmd.Context(), kubeclient, target)
+				if err != nil && !apierrors.IsNotFound(err) {
+					return err
+				}
+				if err != nil || cfg.Backend == "" || cfg.Backend == "none" {
+					enabled = false
+				}
+			}
+
+			if !enabled {
 				switch enableFlags.template {
 				case "zipkin":
-					err := setup.Zipkin(cmd.Context(), p)
+					err := setup.Zipkin(cmd.Context(), p, targets)
 					if err != nil {
 						return err
 					}
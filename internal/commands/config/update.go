@@ -24,16 +24,46 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"knative.dev/client/pkg/kn/flags"
+	"knative.dev/kn-plugin-trace/internal/backend"
+	"knative.dev/kn-plugin-trace/pkg/config"
 
 	"knative.dev/client/pkg/kn/commands"
 )
 
 type configUpdateFlags struct {
-	debug bool
+	debug          bool
+	sampleRate     float64
+	backend        string
+	zipkinEndpoint string
+	component      string
+	namespaces     config.Namespaces
 }
 
 func (c *configUpdateFlags) addFlags(cmd *cobra.Command) {
 	flags.AddBothBoolFlags(cmd.Flags(), &c.debug, "debug", "d", false, "set tracing debug mode.")
+	cmd.Flags().Float64Var(&c.sampleRate, "sample-rate", 0, "set the fraction of requests traced, between 0 and 1. Ignored in debug mode")
+	cmd.Flags().StringVar(&c.backend, "backend", "", "set the tracing backend. One of: zipkin, none")
+	cmd.Flags().StringVar(&c.zipkinEndpoint, "zipkin-endpoint", "", "set the Zipkin endpoint spans are sent to (e.g. http://zipkin.kntools.svc.cluster.local:9411/api/v2/spans)")
+	addComponentFlag(cmd, &c.component)
+	backend.AddNamespaceFlags(cmd, &c.namespaces)
+}
+
+// settings returns the settings set on the command line
+func (c *configUpdateFlags) settings(cmd *cobra.Command) map[string]string {
+	settings := make(map[string]string)
+	if cmd.Flags().Changed("debug") || cmd.Flags().Changed("no-debug") {
+		settings[config.DebugKey] = strconv.FormatBool(c.debug)
+	}
+	if cmd.Flags().Changed("sample-rate") {
+		settings[config.SampleRateKey] = strconv.FormatFloat(c.sampleRate, 'f', -1, 64)
+	}
+	if cmd.Flags().Changed("backend") {
+		settings[config.BackendKey] = c.backend
+	}
+	if cmd.Flags().Changed("zipkin-endpoint") {
+		settings[config.ZipkinEndpointKey] = c.zipkinEndpoint
+	}
+	return settings
 }
 
 // NewUpdateCommand implements 'kn trace config update' command
@@ -59,47 +89,52 @@ func NewUpdateCommand(p *commands.KnParams) *cobra.Command {
 				return fmt.Errorf("failed to update tracing configuration: %w", err)
 			}
 
-			// TODO: alternate eventing installation namespace
-			cm, err := client.CoreV1().ConfigMaps("knative-eventing").Get(cmd.Context(), "config-tracing", metav1.GetOptions{})
+			targets, err := targets(cmd.Context(), p, client, updateflags.component, updateflags.namespaces)
 			if err != nil {
-				if !apierrors.IsNotFound(err) {
-					return fmt.Errorf("\"failed to update tracing configuration: %w", err)
-				}
-
-				// knative eventing hasn't been installed properly.
-				fmt.Println("⚠️ missing config-tracing in the knative-eventing namespace which is an indicator that Knative Eventing hasn't been properly installed (recovering)")
-				cm = &corev1.ConfigMap{
-					ObjectMeta: metav1.ObjectMeta{
-						Name: "config-tracing",
-					},
-					Data: map[string]string{},
-				}
+				return fmt.Errorf("failed to update tracing configuration: %w", err)
 			}
 
-			setDebug := cmd.Flags().Changed("debug") || cmd.Flags().Changed("no-debug")
-			updated := false
+			settings := updateflags.settings(cmd)
+
+			// Every ConfigMap is validated before any is written
+			for _, target := range targets {
+				cm, err := client.CoreV1().ConfigMaps(target.Namespace).Get(cmd.Context(), config.ConfigMapName, metav1.GetOptions{})
+				if err != nil {
+					if !apierrors.IsNotFound(err) {
+						return fmt.Errorf("failed to update tracing configuration: %w", err)
+					}
 
-			if setDebug {
-				debugStr := strconv.FormatBool(updateflags.debug)
+					// knative hasn't been installed properly.
+					fmt.Printf("⚠️ missing %s in the %s namespace which is an indicator that Knative %s hasn't been properly installed (creating it)\n", config.ConfigMapName, target.Namespace, target.Component)
+					cm = &corev1.ConfigMap{}
+				}
 
-				debug, ok := cm.Data["debug"]
-				if !ok || debug != debugStr {
-					cm.Data["debug"] = debugStr
-					updated = true
+				if _, _, err := config.Apply(cm.Data, settings); err != nil {
+					return fmt.Errorf("failed to update tracing configuration of %s: %w", target, err)
 				}
 			}
 
-			if updated {
-				_, err = client.CoreV1().ConfigMaps("knative-eventing").Update(cmd.Context(), cm, metav1.UpdateOptions{})
+			for _, target := range targets {
+				var changes []config.Change
+				_, err := config.Write(cmd.Context(), client, target, func(cm *corev1.ConfigMap) (bool, error) {
+					var err error
+					cm.Data, changes, err = config.Apply(cm.Data, settings)
+					return len(changes) > 0, err
+				})
 				if err != nil {
-					return err
+					return fmt.Errorf("failed to update tracing configuration of %s: %w", target, err)
 				}
 
-				fmt.Println("✔️tracing configuration successfully modified")
-				return nil
-			}
+				if len(changes) == 0 {
+					fmt.Printf("✔️tracing configuration of %s unchanged\n", target)
+					continue
+				}
 
-			fmt.Println("✔️tracing configuration unchanged")
+				fmt.Printf("✔️tracing configuration of %s successfully modified\n", target)
+				for _, change := range changes {
+					fmt.Printf("  %s\n", change)
+				}
+			}
 			return nil
 		},
 	}
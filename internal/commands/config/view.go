@@ -15,19 +15,28 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"knative.dev/kn-plugin-trace/internal/backend"
 	"knative.dev/kn-plugin-trace/internal/output"
-	"knative.dev/kn-plugin-trace/pkg/zipkin"
 
 	"knative.dev/client/pkg/kn/commands"
 	"knative.dev/kn-plugin-trace/pkg/config"
+	tracingconfig "knative.dev/pkg/tracing/config"
 )
 
 // NewViewCommand implements 'kn trace config info' command
 func NewViewCommand(p *commands.KnParams) *cobra.Command {
+	var (
+		backendFlags backend.Flags
+		component    string
+	)
 
 	cmd := &cobra.Command{
 		Use:   "view",
@@ -43,49 +52,83 @@ func NewViewCommand(p *commands.KnParams) *cobra.Command {
 				return err
 			}
 
-			cfg, err := config.Load(cmd.Context(), kubeclient)
+			targets, err := targets(cmd.Context(), p, kubeclient, component, backendFlags.Namespaces)
 			if err != nil {
 				return err
 			}
 
-			if cfg.Backend == "zipkin" || cfg.Backend == "none" {
-				output.Checkmark()
-			} else {
-				output.Error()
-			}
-
-			fmt.Printf("backend: %s\n", cfg.Backend)
+			var first *tracingconfig.Config
+			for i, target := range targets {
+				if i > 0 {
+					fmt.Println()
+				}
+				fmt.Printf("%s:\n", target)
 
-			if cfg.Backend == "zipkin" {
-				if cfg.ZipkinEndpoint == "" {
+				cfg, err := config.Load(cmd.Context(), kubeclient, target)
+				if apierrors.IsNotFound(err) {
 					output.Error()
-				} else {
-					output.Checkmark()
-					fmt.Printf("zipkinEndpoint: %s\n", cfg.ZipkinEndpoint)
-
-					if _, err := zipkin.Connect(cmd.Context(), cfg.ZipkinEndpoint, restcfg); err == nil {
-						output.Checkmark()
-						fmt.Println("Reachable")
-					} else {
-						output.Error()
-						fmt.Println("Unreachable")
-					}
+					fmt.Printf("missing %s\n", config.ConfigMapName)
+					continue
 				}
-			}
+				if err != nil {
+					return err
+				}
+				view(cmd.Context(), cfg, restcfg, backendFlags)
 
-			if cfg.Debug == false {
-				output.Warning()
-				fmt.Printf("debug: %t (only some traces will be displayed when running kn trace show)\n", cfg.Debug)
-			} else {
-				output.Checkmark()
-				fmt.Printf("debug: %t\n", cfg.Debug)
+				if first == nil {
+					first = cfg
+				} else if diffs := config.Differences(first, cfg); len(diffs) > 0 {
+					fmt.Println()
+					output.Warning()
+					fmt.Printf("%s differs from %s: %s (traces may be incomplete)\n", target, targets[0], strings.Join(diffs, ", "))
+				}
 			}
-
-			output.Checkmark()
-			fmt.Printf("sample-rate: %f\n", cfg.SampleRate)
 			return nil
 		},
 	}
 
+	backendFlags.AddFlags(cmd)
+	addComponentFlag(cmd, &component)
+
 	return cmd
 }
+
+// view prints the given tracing configuration
+func view(ctx context.Context, cfg *tracingconfig.Config, restcfg *rest.Config, backendFlags backend.Flags) {
+	if cfg.Backend == "zipkin" || cfg.Backend == "none" {
+		output.Checkmark()
+	} else {
+		output.Error()
+	}
+
+	fmt.Printf("backend: %s\n", cfg.Backend)
+
+	if cfg.Backend == "zipkin" {
+		if cfg.ZipkinEndpoint == "" {
+			output.Error()
+		} else {
+			output.Checkmark()
+			fmt.Printf("zipkinEndpoint: %s\n", cfg.ZipkinEndpoint)
+
+			if store, err := backend.ConnectEndpoint(ctx, cfg.ZipkinEndpoint, restcfg, backendFlags); err == nil {
+				store.Close()
+				output.Checkmark()
+				fmt.Println("Reachable")
+			} else {
+				output.Error()
+				fmt.Println("Unreachable")
+			}
+		}
+	}
+
+	if cfg.Debug == false {
+		output.Warning()
+		fmt.Printf("debug: %t (only some traces will be displayed when running kn trace show)\n", cfg.Debug)
+	} else {
+		output.Checkmark()
+		fmt.Printf("debug: %t\n", cfg.Debug)
+	}
+
+	output.Checkmark()
+	fmt.Printf("sample-rate: %f\n", cfg.SampleRate)
+}
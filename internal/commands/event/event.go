@@ -0,0 +1,165 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"knative.dev/kn-plugin-trace/internal/backend"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+
+	"knative.dev/client/pkg/kn/commands"
+)
+
+type eventFlags struct {
+	verbose bool
+	since   time.Duration
+	limit   int
+
+	backendFlags backend.Flags
+}
+
+func (c *eventFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&c.verbose, "verbose", "v", false, "show span tags")
+	cmd.Flags().DurationVar(&c.since, "since", 0, "only look for the event in traces more recent than this duration (e.g. 1h). Default to all traces")
+	cmd.Flags().IntVar(&c.limit, "limit", 200, "maximum number of traces to search for deliveries of the event")
+	c.backendFlags.AddFlags(cmd)
+}
+
+// NewEventCommand implements 'kn trace event' command
+func NewEventCommand(p *commands.KnParams) *cobra.Command {
+	var eventflags eventFlags
+
+	cmd := &cobra.Command{
+		Use:   "event <cloudevents.id>",
+		Short: "Show the path of a CloudEvent",
+		Long:  "Show the path of a CloudEvent through sources, brokers, triggers, channels and subscribers",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			eventID := args[0]
+
+			if eventflags.limit <= 0 {
+				return errors.New("--limit must be positive")
+			}
+
+			store, err := backend.Connect(cmd.Context(), p, eventflags.backendFlags)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			spans, err := findEvent(cmd.Context(), store, eventID, eventflags.since, eventflags.limit)
+			if err != nil {
+				return err
+			}
+
+			if len(spans) == 0 {
+				return fmt.Errorf("no trace found for event %q", eventID)
+			}
+
+			printPath(os.Stdout, eventID, spans, eventflags.verbose)
+			return nil
+		},
+	}
+
+	eventflags.addFlags(cmd)
+
+	return cmd
+}
+
+// findEvent returns the spans of all the traces the given event went through, in start order
+func findEvent(ctx context.Context, store trace.Store, eventID string, since time.Duration, limit int) ([]trace.Span, error) {
+	traces, err := store.Search(ctx, trace.Query{
+		Tags:     map[string]string{trace.CloudEventIDTag: eventID},
+		Lookback: since,
+		Limit:    limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The search may return partial traces so pull the whole traces
+	// the event went through.
+	var spans []trace.Span
+	seen := make(map[string]bool)
+	for _, t := range traces {
+		for _, span := range t {
+			if seen[span.TraceID] || !trace.HasCloudEventID(span, eventID) {
+				continue
+			}
+			seen[span.TraceID] = true
+
+			full, err := store.Trace(ctx, span.TraceID)
+			if err != nil {
+				return nil, err
+			}
+			spans = append(spans, full...)
+		}
+	}
+
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].Timestamp.Before(spans[j].Timestamp)
+	})
+	return spans, nil
+}
+
+// printPath prints the spans the event went through, in the given order. Nothing
+// is printed when there is no span.
+func printPath(out io.Writer, eventID string, spans []trace.Span, verbose bool) {
+	if len(spans) == 0 {
+		return
+	}
+
+	first := spans[0]
+	for _, span := range spans {
+		if trace.HasCloudEventID(span, eventID) {
+			first = span
+			break
+		}
+	}
+
+	fmt.Fprintf(out, "Event %s\n", eventID)
+	fmt.Fprintf(out, "  source: %s\n", first.Tags[trace.CloudEventSourceTag])
+	fmt.Fprintf(out, "  type:   %s\n\n", first.Tags[trace.CloudEventTypeTag])
+
+	start := spans[0].Timestamp
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "OFFSET\tDURATION\tCOMPONENT\tSERVICE\tSPAN")
+	for _, span := range spans {
+		fmt.Fprintf(w, "+%s\t%s\t%s\t%s\t%s\n", span.Timestamp.Sub(start), span.Duration, trace.Component(span), span.Service(), span.Name)
+
+		if verbose {
+			keys := make([]string, 0, len(span.Tags))
+			for key := range span.Tags {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			for _, key := range keys {
+				fmt.Fprintf(w, "\t\t\t  %s=%s\n", key, span.Tags[key])
+			}
+		}
+	}
+	w.Flush()
+}
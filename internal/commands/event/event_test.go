@@ -0,0 +1,116 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+	"knative.dev/kn-plugin-trace/pkg/trace/fake"
+)
+
+// countingStore records the traces fetched by ID
+type countingStore struct {
+	*fake.Store
+	fetched map[string]int
+}
+
+func (s *countingStore) Trace(ctx context.Context, traceID string) ([]trace.Span, error) {
+	s.fetched[traceID]++
+	return s.Store.Trace(ctx, traceID)
+}
+
+func eventSpan(traceID, id, parentID, name, service, eventID string, timestamp time.Time) trace.Span {
+	span := trace.Span{
+		TraceID:       traceID,
+		ID:            id,
+		ParentID:      parentID,
+		Name:          name,
+		Kind:          trace.KindServer,
+		Timestamp:     timestamp,
+		Duration:      time.Millisecond,
+		LocalEndpoint: &trace.Endpoint{ServiceName: service},
+	}
+	if eventID != "" {
+		span.Tags = map[string]string{
+			trace.CloudEventIDTag:     eventID,
+			trace.CloudEventSourceTag: "/demo",
+			trace.CloudEventTypeTag:   "dev.knative.demo",
+		}
+	}
+	return span
+}
+
+func TestFindEvent(t *testing.T) {
+	start := time.Now().Add(-time.Minute)
+
+	// The event is delivered, then retried in a second trace. Each trace
+	// holds several spans carrying the event ID.
+	store := &countingStore{
+		Store: fake.NewStore(
+			eventSpan("b", "4", "", "broker:default", "broker-ingress.knative-eventing", "42", start.Add(time.Second)),
+			eventSpan("b", "5", "4", "trigger:demo", "broker-filter.knative-eventing", "42", start.Add(time.Second+time.Millisecond)),
+			eventSpan("a", "1", "", "broker:default", "broker-ingress.knative-eventing", "42", start),
+			eventSpan("a", "2", "1", "trigger:demo", "broker-filter.knative-eventing", "42", start.Add(time.Millisecond)),
+			eventSpan("a", "3", "2", "POST /", "demo.default", "", start.Add(2*time.Millisecond)),
+			eventSpan("c", "6", "", "broker:default", "broker-ingress.knative-eventing", "43", start),
+		),
+		fetched: make(map[string]int),
+	}
+
+	spans, err := findEvent(context.Background(), store, "42", 0, 200)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, store.fetched, map[string]int{"a": 1, "b": 1})
+	assert.Equal(t, store.Queries[0].Limit, 200)
+
+	var ids []string
+	for _, span := range spans {
+		ids = append(ids, span.ID)
+	}
+	assert.DeepEqual(t, ids, []string{"1", "2", "3", "4", "5"})
+
+	spans, err = findEvent(context.Background(), store, "unknown", 0, 200)
+	assert.NilError(t, err)
+	assert.Equal(t, len(spans), 0)
+}
+
+func TestPrintPath(t *testing.T) {
+	start := time.Date(2021, 11, 4, 10, 0, 0, 0, time.UTC)
+	spans := []trace.Span{
+		eventSpan("a", "1", "", "broker:default", "broker-ingress.knative-eventing", "42", start),
+		eventSpan("a", "2", "1", "trigger:demo", "broker-filter.knative-eventing", "42", start.Add(time.Millisecond)),
+		eventSpan("a", "3", "2", "POST /", "demo.default", "", start.Add(3*time.Millisecond)),
+	}
+
+	out := new(bytes.Buffer)
+	printPath(out, "42", spans, false)
+	assert.Equal(t, out.String(), `Event 42
+  source: /demo
+  type:   dev.knative.demo
+
+OFFSET  DURATION  COMPONENT   SERVICE                          SPAN
++0s     1ms       broker      broker-ingress.knative-eventing  broker:default
++1ms    1ms       trigger     broker-filter.knative-eventing   trigger:demo
++3ms    1ms       subscriber  demo.default                     POST /
+`)
+
+	out.Reset()
+	printPath(out, "42", nil, false)
+	assert.Equal(t, out.String(), "")
+}
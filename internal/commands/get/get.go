@@ -0,0 +1,138 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package get
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"knative.dev/kn-plugin-trace/internal/backend"
+	"knative.dev/kn-plugin-trace/internal/output"
+	"knative.dev/kn-plugin-trace/pkg/proxy"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+
+	"knative.dev/client/pkg/kn/commands"
+)
+
+type getFlags struct {
+	verbose bool
+	view    string
+
+	printFlags output.PrintFlags
+
+	backendFlags backend.Flags
+}
+
+func (c *getFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&c.verbose, "verbose", "v", false, "show span tags")
+	cmd.Flags().StringVar(&c.view, "view", "tree", "how to display the trace. One of: tree, timeline, waterfall")
+
+	c.backendFlags.AddFlags(cmd)
+
+	c.printFlags.AddFlags(cmd)
+}
+
+func (c *getFlags) validate() error {
+	switch c.view {
+	case "tree", "timeline", "waterfall":
+	default:
+		return fmt.Errorf("invalid view %q. Must be one of: tree, timeline, waterfall", c.view)
+	}
+
+	return c.printFlags.Validate()
+}
+
+// NewGetCommand implements 'kn trace get' command
+func NewGetCommand(p *commands.KnParams) *cobra.Command {
+	var getflags getFlags
+
+	cmd := &cobra.Command{
+		Use:   "get <trace-id>",
+		Short: "Show a trace",
+		Long:  "Show all the spans of a trace, for instance the one identified by the X-B3-TraceId header of an event",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			traceID, err := normalizeTraceID(args[0])
+			if err != nil {
+				return err
+			}
+
+			if err := getflags.validate(); err != nil {
+				return err
+			}
+
+			store, err := backend.Connect(cmd.Context(), p, getflags.backendFlags)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			spans, err := getTrace(cmd.Context(), store, traceID)
+			if err != nil {
+				return err
+			}
+
+			if getflags.printFlags.Structured() {
+				return getflags.printFlags.Print(os.Stdout, spans, nil)
+			}
+
+			for _, tree := range trace.Build(spans) {
+				switch getflags.view {
+				case "timeline":
+					output.PrintTimeline(os.Stdout, tree, getflags.verbose)
+				case "waterfall":
+					output.PrintWaterfall(os.Stdout, tree, output.TerminalWidth(os.Stdout))
+				default:
+					output.PrintTree(os.Stdout, tree, getflags.verbose)
+				}
+			}
+			return nil
+		},
+	}
+
+	getflags.addFlags(cmd)
+
+	return cmd
+}
+
+// normalizeTraceID returns the lowercase form of a hex-encoded trace ID.
+// B3 trace IDs are 64 or 128 bits long.
+func normalizeTraceID(id string) (string, error) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if len(id) == 0 || len(id) > 32 {
+		return "", fmt.Errorf("invalid trace ID %q: must be 1 to 32 hex characters", id)
+	}
+	for _, c := range id {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return "", fmt.Errorf("invalid trace ID %q: must be 1 to 32 hex characters", id)
+		}
+	}
+	return id, nil
+}
+
+// getTrace returns the spans of the given trace
+func getTrace(ctx context.Context, store trace.Store, traceID string) ([]trace.Span, error) {
+	spans, err := store.Trace(ctx, traceID)
+	if proxy.IsNotFound(err) || (err == nil && len(spans) == 0) {
+		return nil, fmt.Errorf("trace %q not found", traceID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return spans, nil
+}
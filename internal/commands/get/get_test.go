@@ -0,0 +1,53 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package get
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+	"knative.dev/kn-plugin-trace/pkg/trace/fake"
+)
+
+func TestNormalizeTraceID(t *testing.T) {
+	id, err := normalizeTraceID(" 463AC35C9F6413AD48485A3953BB6124 ")
+	assert.NilError(t, err)
+	assert.Equal(t, id, "463ac35c9f6413ad48485a3953bb6124")
+
+	_, err = normalizeTraceID("not-a-trace")
+	assert.ErrorContains(t, err, "invalid trace ID")
+
+	_, err = normalizeTraceID("463ac35c9f6413ad48485a3953bb61240")
+	assert.ErrorContains(t, err, "invalid trace ID")
+}
+
+func TestGetTrace(t *testing.T) {
+	now := time.Now()
+	store := fake.NewStore(
+		trace.Span{TraceID: "abc", ID: "1", Name: "root", Timestamp: now, Duration: time.Second},
+		trace.Span{TraceID: "abc", ID: "2", ParentID: "1", Name: "child", Timestamp: now.Add(time.Millisecond), Duration: time.Millisecond},
+		trace.Span{TraceID: "def", ID: "3", Name: "other", Timestamp: now, Duration: time.Second},
+	)
+
+	spans, err := getTrace(context.Background(), store, "abc")
+	assert.NilError(t, err)
+	assert.Equal(t, len(spans), 2)
+
+	_, err = getTrace(context.Background(), store, "123")
+	assert.ErrorContains(t, err, "not found")
+}
@@ -0,0 +1,133 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"knative.dev/kn-plugin-trace/internal/backend"
+	"knative.dev/kn-plugin-trace/internal/output"
+	"knative.dev/kn-plugin-trace/internal/poller"
+	"knative.dev/kn-plugin-trace/pkg/proxy"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+
+	"knative.dev/client/pkg/kn/commands"
+)
+
+type graphFlags struct {
+	output   string
+	since    time.Duration
+	derive   bool
+	services []string
+	limit    int
+
+	backendFlags backend.Flags
+}
+
+func (c *graphFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&c.output, "output", "o", output.GraphASCII, "output format. One of: "+strings.Join(output.GraphFormats, ", "))
+	cmd.Flags().DurationVar(&c.since, "since", time.Hour, "only account for calls more recent than this duration")
+	cmd.Flags().BoolVar(&c.derive, "derive", false, "compute the graph from spans rather than from the dependencies API of the backend")
+	cmd.Flags().StringSliceVar(&c.services, "service", nil, "when computing the graph from spans, only use traces going through this service. Can be repeated")
+	cmd.Flags().IntVar(&c.limit, "limit", 200, "when computing the graph from spans, maximum number of traces to fetch per service")
+	c.backendFlags.AddFlags(cmd)
+}
+
+func (c *graphFlags) validate() error {
+	found := false
+	for _, format := range output.GraphFormats {
+		found = found || format == c.output
+	}
+	if !found {
+		return fmt.Errorf("invalid output format %q. Must be one of: %s", c.output, strings.Join(output.GraphFormats, ", "))
+	}
+
+	if c.since <= 0 || c.limit <= 0 {
+		return errors.New("--since and --limit must be positive")
+	}
+	return nil
+}
+
+// NewGraphCommand implements 'kn trace graph' command
+func NewGraphCommand(p *commands.KnParams) *cobra.Command {
+	var graphflags graphFlags
+
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Show the calls between services",
+		Long: `Show the calls between sources, brokers, triggers, channels and services, with call and error counts.
+
+The graph is read from the dependencies API of the backend. It is computed from
+spans when the backend has no dependencies for the time window, or with --derive.`,
+		Example: `  # Render the event topology of the last 24 hours with Graphviz
+  kn trace graph --since 24h -o dot | dot -Tsvg > topology.svg`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := graphflags.validate(); err != nil {
+				return err
+			}
+
+			store, err := backend.Connect(cmd.Context(), p, graphflags.backendFlags)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			deps, components, err := dependencies(cmd.Context(), store, graphflags, time.Now())
+			if err != nil {
+				return err
+			}
+
+			return output.PrintGraph(os.Stdout, graphflags.output, deps, components)
+		},
+	}
+
+	graphflags.addFlags(cmd)
+
+	return cmd
+}
+
+// dependencies returns the calls between services during the window ending at end,
+// along with the role of each service.
+func dependencies(ctx context.Context, store trace.Store, flags graphFlags, end time.Time) ([]trace.Dependency, map[string]string, error) {
+	if !flags.derive {
+		deps, err := store.Dependencies(ctx, end, flags.since)
+		if err != nil && !proxy.IsNotFound(err) {
+			return nil, nil, err
+		}
+
+		// Roles can only be guessed from the service names
+		if len(deps) > 0 {
+			var spans []trace.Span
+			for _, dep := range deps {
+				spans = append(spans,
+					trace.Span{LocalEndpoint: &trace.Endpoint{ServiceName: dep.Parent}},
+					trace.Span{LocalEndpoint: &trace.Endpoint{ServiceName: dep.Child}})
+			}
+			return deps, trace.Components(spans), nil
+		}
+	}
+
+	spans, err := poller.New(store, flags.services, poller.LateShow, 0).Poll(ctx, trace.Query{Limit: flags.limit}, end.Add(-flags.since), end)
+	if err != nil {
+		return nil, nil, err
+	}
+	return trace.DeriveDependencies(spans), trace.Components(spans), nil
+}
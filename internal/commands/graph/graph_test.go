@@ -0,0 +1,50 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+	"knative.dev/kn-plugin-trace/pkg/trace/fake"
+)
+
+func TestDependencies(t *testing.T) {
+	now := time.Date(2021, 11, 4, 10, 0, 0, 0, time.UTC)
+	store := fake.NewStore(
+		trace.Span{TraceID: "1", ID: "a", Name: "broker:default", Timestamp: now.Add(-time.Minute), Duration: time.Millisecond,
+			LocalEndpoint: &trace.Endpoint{ServiceName: "broker-ingress.knative-eventing"}},
+		trace.Span{TraceID: "1", ID: "b", ParentID: "a", Kind: trace.KindServer, Timestamp: now.Add(-time.Minute), Duration: time.Millisecond,
+			LocalEndpoint: &trace.Endpoint{ServiceName: "event-display"}},
+	)
+
+	expected := []trace.Dependency{{Parent: "broker-ingress.knative-eventing", Child: "event-display", CallCount: 1}}
+
+	deps, components, err := dependencies(context.Background(), store, graphFlags{since: time.Hour, limit: 10}, now)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, deps, expected)
+	assert.DeepEqual(t, components, map[string]string{"broker-ingress.knative-eventing": trace.ComponentBroker})
+
+	deps, components, err = dependencies(context.Background(), store, graphFlags{since: time.Hour, limit: 10, derive: true}, now)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, deps, expected)
+	assert.DeepEqual(t, components, map[string]string{
+		"broker-ingress.knative-eventing": trace.ComponentBroker,
+		"event-display":                   trace.ComponentSubscriber,
+	})
+}
@@ -0,0 +1,75 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package show
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"knative.dev/kn-plugin-trace/internal/output"
+	"knative.dev/kn-plugin-trace/pkg/resolver"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+
+	"knative.dev/client/pkg/kn/commands"
+)
+
+// resourcesRefresh is how often Knative objects are listed again while following
+const resourcesRefresh = time.Minute
+
+// resources resolves spans to the Knative objects they belong to
+type resources struct {
+	p        *commands.KnParams
+	resolver *resolver.Resolver
+	loaded   time.Time
+	warned   bool
+}
+
+func newResources(p *commands.KnParams) *resources {
+	return &resources{p: p, resolver: resolver.New()}
+}
+
+// annotate sets the resource of the given spans, listing the objects of the
+// cluster when they have not been listed recently. Brokers and Triggers are
+// still resolved when objects cannot be listed.
+func (r *resources) annotate(ctx context.Context, spans []trace.Span, now time.Time) {
+	if len(spans) > 0 && now.Sub(r.loaded) > resourcesRefresh {
+		r.loaded = now
+
+		res := resolver.New()
+		if err := res.Load(ctx, r.clients()); err != nil && !r.warned {
+			r.warned = true
+			output.Warn(os.Stderr, "some Knative resources cannot be listed, spans may not be matched to their resources: %v", err)
+		}
+		r.resolver = res
+	}
+
+	r.resolver.Annotate(spans)
+}
+
+// clients returns the clients listing objects in all namespaces
+func (r *resources) clients() resolver.Clients {
+	var clients resolver.Clients
+	if client, err := r.p.NewEventingClient(""); err == nil {
+		clients.Eventing = client
+	}
+	if client, err := r.p.NewMessagingClient(""); err == nil {
+		clients.Messaging = client
+	}
+	if client, err := r.p.NewDynamicClient(""); err == nil {
+		clients.Dynamic = client
+	}
+	return clients
+}
@@ -15,29 +15,196 @@
 package show
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"time"
 
-	"github.com/openzipkin/zipkin-go/model"
 	"github.com/spf13/cobra"
-	"k8s.io/client-go/kubernetes"
-	"knative.dev/kn-plugin-trace/pkg/zipkin"
+	"knative.dev/kn-plugin-trace/internal/backend"
+	"knative.dev/kn-plugin-trace/internal/output"
+	"knative.dev/kn-plugin-trace/internal/poller"
+	"knative.dev/kn-plugin-trace/pkg/resolver"
+	"knative.dev/kn-plugin-trace/pkg/trace"
 
 	"knative.dev/client/pkg/kn/commands"
-
-	"knative.dev/kn-plugin-trace/pkg/config"
 )
 
 type showFlags struct {
 	follow  bool
 	verbose bool
 	all     bool
+	view    string
+
+	services    []string
+	source      string
+	sourceObj   string
+	eventType   string
+	broker      string
+	trigger     string
+	since       string
+	until       string
+	minDuration time.Duration
+	limit       int
+	late        string
+	overlap     time.Duration
+	delay       time.Duration
+	watermark   bool
+	interval    time.Duration
+
+	printFlags output.PrintFlags
+
+	backendFlags backend.Flags
 }
 
 func (c *showFlags) addFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&c.follow, "follow", "f", false, "stream traces")
 	cmd.Flags().BoolVarP(&c.verbose, "verbose", "v", false, "show all traces data")
-	cmd.Flags().BoolVarP(&c.all, "all", "a", false, "show non-cloudevents traces")
+	cmd.Flags().BoolVarP(&c.all, "all", "a", false, "show all traces, not only CloudEvents deliveries and Knative Serving requests")
+	cmd.Flags().StringVar(&c.view, "view", "list", "how to display traces. One of: list, tree, waterfall")
+
+	cmd.Flags().StringSliceVar(&c.services, "service", nil, "only show traces going through this service. Can be repeated")
+	cmd.Flags().StringVar(&c.source, "source", "", "only show traces of CloudEvents with this source")
+	cmd.Flags().StringVar(&c.sourceObj, "source-object", "", "only show traces of CloudEvents sent by this Source object, named <name> or <namespace>/<name>")
+	cmd.Flags().StringVar(&c.eventType, "type", "", "only show traces of CloudEvents with this type")
+	cmd.Flags().StringVar(&c.broker, "broker", "", "only show spans of this Broker and its Triggers, named <name> or <namespace>/<name>")
+	cmd.Flags().StringVar(&c.trigger, "trigger", "", "only show spans of this Trigger, named <name> or <namespace>/<name>")
+	cmd.Flags().StringVar(&c.since, "since", "", "only show traces more recent than a relative duration (e.g. 10m) or a RFC3339 timestamp. Default to all traces")
+	cmd.Flags().StringVar(&c.until, "until", "", "only show traces older than a relative duration (e.g. 10m) or a RFC3339 timestamp. Default to now")
+	cmd.Flags().DurationVar(&c.minDuration, "min-duration", 0, "only show traces lasting at least this duration (e.g. 100ms)")
+	cmd.Flags().IntVar(&c.limit, "limit", 200, "maximum number of traces to fetch per service")
+	cmd.Flags().DurationVar(&c.interval, "interval", time.Second, "when following, how long to wait between polls")
+	cmd.Flags().DurationVar(&c.overlap, "overlap", 30*time.Second, "when following, how far back each poll searches before the previous one ended, to catch spans reported late")
+	cmd.Flags().DurationVar(&c.delay, "delay", 0, "when following, how long to wait before searching for spans, to let them be reported and absorb clock skew with the cluster")
+	cmd.Flags().BoolVar(&c.watermark, "watermark", false, "when following, print on stderr the time before which all spans have been displayed")
+	cmd.Flags().StringVar(&c.late, "late", poller.LateShow, "what to do with spans reported after the time window they started in has been displayed. One of: show, drop")
+
+	c.backendFlags.AddFlags(cmd)
+
+	c.printFlags.AddFlags(cmd)
+}
+
+func (c *showFlags) validate() error {
+	switch c.view {
+	case "list", "tree", "waterfall":
+	default:
+		return fmt.Errorf("invalid view %q. Must be one of: list, tree, waterfall", c.view)
+	}
+
+	switch c.late {
+	case poller.LateShow, poller.LateDrop:
+	default:
+		return fmt.Errorf("invalid --late %q. Must be one of: show, drop", c.late)
+	}
+
+	if c.overlap < 0 || c.delay < 0 {
+		return errors.New("--overlap and --delay must not be negative")
+	}
+
+	if c.interval <= 0 {
+		return errors.New("--interval must be positive")
+	}
+
+	if c.follow && c.until != "" {
+		return errors.New("--until cannot be used with --follow")
+	}
+
+	if c.limit <= 0 {
+		return errors.New("--limit must be positive")
+	}
+
+	return c.printFlags.Validate()
+}
+
+// query returns the trace search matching the flags, without time window
+func (c *showFlags) query() trace.Query {
+	tags := make(map[string]string)
+	if c.source != "" {
+		tags[trace.CloudEventSourceTag] = c.source
+	}
+	// Trigger spans are tagged with their destination
+	if strings.Contains(c.trigger, "/") {
+		ns, name := splitName(c.trigger)
+		tags[trace.MessagingDestinationTag] = "trigger:" + name + "." + ns
+	}
+	if c.eventType != "" {
+		tags[trace.CloudEventTypeTag] = c.eventType
+	}
+
+	return trace.Query{
+		Tags:        tags,
+		MinDuration: c.minDuration,
+		Limit:       c.limit,
+	}
+}
+
+// accept returns true when the span should be displayed. Spans must have been
+// annotated with their resources by the resolver.
+func (c *showFlags) accept(res *resolver.Resolver, span trace.Span) bool {
+	if !c.all && !hasCloudEventTagId(span) && !trace.IsServingRequest(span) {
+		return false
+	}
+	if c.source != "" && span.Tags[trace.CloudEventSourceTag] != c.source {
+		return false
+	}
+	if c.sourceObj != "" && !resolver.MatchSource(span.Resource, c.sourceObj) {
+		return false
+	}
+	if c.broker != "" && !res.MatchBroker(span.Resource, c.broker) {
+		return false
+	}
+	if c.trigger != "" && !resolver.Match(span.Resource, "Trigger", c.trigger) {
+		return false
+	}
+	if c.eventType != "" && span.Tags[trace.CloudEventTypeTag] != c.eventType {
+		return false
+	}
+	return true
+}
+
+// splitName splits <namespace>/<name>
+func splitName(value string) (namespace, name string) {
+	parts := strings.SplitN(value, "/", 2)
+	return parts[0], parts[1]
+}
+
+// window returns the initial time window to search traces in
+func (c *showFlags) window(now time.Time) (since time.Time, until time.Time, err error) {
+	since = time.UnixMilli(0)
+	if c.since != "" {
+		since, err = parseTime(c.since, now)
+		if err != nil {
+			return since, until, fmt.Errorf("invalid --since: %w", err)
+		}
+	}
+
+	until = now.Add(-c.delay)
+	if c.until != "" {
+		until, err = parseTime(c.until, now)
+		if err != nil {
+			return since, until, fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+
+	if !since.Before(until) {
+		return since, until, errors.New("--since must be before --until")
+	}
+	return since, until, nil
+}
+
+// parseTime parses either a duration relative to now or a RFC3339 timestamp
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return t, fmt.Errorf("%q is neither a duration nor a RFC3339 timestamp", value)
+	}
+	return t, nil
 }
 
 // NewShowCommand is the command for showing traces
@@ -49,52 +216,78 @@ func NewShowCommand(p *commands.KnParams) *cobra.Command {
 		Short: "Show traces",
 
 		RunE: func(cmd *cobra.Command, args []string) error {
-			restcfg, err := p.RestConfig()
-			if err != nil {
+			if err := showflags.validate(); err != nil {
 				return err
 			}
 
-			// Read Tracing configuration
-
-			kubeclient, err := kubernetes.NewForConfig(restcfg)
+			store, err := backend.Connect(cmd.Context(), p, showflags.backendFlags)
 			if err != nil {
 				return err
 			}
+			defer store.Close()
 
-			cfg, err := config.Load(cmd.Context(), kubeclient)
+			since, until, err := showflags.window(time.Now())
 			if err != nil {
 				return err
 			}
 
-			if err := config.Validate(cfg); err != nil {
-				return err
+			ctx := cmd.Context()
+			spanPoller := poller.New(store, showflags.services, showflags.late, showflags.overlap)
+			schedule := poller.Schedule{Interval: showflags.interval, Delay: showflags.delay}
+			displayed := newSummary(time.Now())
+			resources := newResources(p)
+			accept := func(span trace.Span) bool {
+				return showflags.accept(resources.resolver, span)
 			}
 
-			// Create connection to Zipkin
-			connection, err := zipkin.Connect(cmd.Context(), cfg.ZipkinEndpoint, restcfg)
-			if err != nil {
-				return err
-			}
+			var failure error
+			spanPoller.Follow(ctx, showflags.query(), since, until, schedule, func(r poller.Result) bool {
+				if r.Err != nil {
+					if !showflags.follow {
+						failure = r.Err
+						return false
+					}
 
-			since := time.UnixMilli(0)
-			for {
-				now := time.Now()
+					// Keep streaming through transient errors
+					output.Warn(os.Stderr, "failed to fetch traces (retrying in %s): %v", r.Next.Round(time.Millisecond), r.Err)
+					return true
+				}
 
-				err := showSpans(connection, now, since, showflags.verbose, showflags.all)
-				if err != nil {
-					return err
+				resources.annotate(ctx, r.Spans, time.Now())
+				displayed.add(r.Spans, accept)
+
+				if showflags.printFlags.Structured() {
+					// Don't print empty lists while following
+					if len(r.Spans) > 0 || !showflags.follow {
+						if err := showflags.printFlags.Print(os.Stdout, r.Spans, accept); err != nil {
+							failure = err
+							return false
+						}
+					}
+				} else if showflags.view == "tree" {
+					showTrees(r.Spans, showflags.verbose, accept)
+				} else if showflags.view == "waterfall" {
+					showWaterfalls(r.Spans, accept)
+				} else {
+					showSpans(r.Spans, showflags.verbose, accept)
 				}
 
 				if !showflags.follow {
-					return nil
+					return false
 				}
 
-				time.Sleep(1 * time.Second)
-				since = now
-			}
-
-			return nil
+				if showflags.watermark {
+					fmt.Fprintf(os.Stderr, "watermark: %s\n", spanPoller.Watermark().UTC().Format(time.RFC3339Nano))
+				}
+				return true
+			})
 
+			if ctx.Err() != nil {
+				// Interrupted
+				displayed.print(os.Stderr, spanPoller.Watermark())
+				return nil
+			}
+			return failure
 		},
 	}
 
@@ -103,63 +296,87 @@ func NewShowCommand(p *commands.KnParams) *cobra.Command {
 	return showCmd
 }
 
-func showSpans(connection *zipkin.Connection, now time.Time, since time.Time, verbose bool, all bool) error {
-	endTs := now
-	lookback := endTs.Sub(since).Milliseconds()
-
-	// Get all traces
-	services, err := connection.Services()
-	if err != nil {
-		return err
-	}
+func showSpans(spans []trace.Span, verbose bool, accept func(trace.Span) bool) {
+	for _, span := range spans {
+		if accept(span) {
+			if !hasCloudEventTagId(span) && trace.IsServingRequest(span) {
+				showRequest(span)
+			} else if span.Resource != nil {
+				fmt.Printf("%s %s %s %s\n", span.Tags["cloudevents.source"], span.Tags["cloudevents.id"], span.Tags["cloudevents.type"], span.Resource)
+			} else {
+				fmt.Printf("%s %s %s\n", span.Tags["cloudevents.source"], span.Tags["cloudevents.id"], span.Tags["cloudevents.type"])
+			}
 
-	for _, svc := range services {
-		spans, err := connection.Spans(svc, endTs.UnixMilli(), lookback)
+			if verbose {
+				if span.LocalEndpoint != nil {
+					fmt.Printf("  %s\n", span.LocalEndpoint.ServiceName)
 
-		if err != nil {
-			return err
-		}
+				}
 
-		for _, span1 := range spans {
-			for _, span := range span1 {
-				if all || hasCloudEventTagId(span) {
-					fmt.Printf("%s %s %s\n", span.Tags["cloudevents.source"], span.Tags["cloudevents.id"], span.Tags["cloudevents.type"])
+				if span.RemoteEndpoint != nil {
+					fmt.Printf("  %s\n", span.RemoteEndpoint.ServiceName)
+				}
 
-					if verbose {
-						if span.LocalEndpoint != nil {
-							fmt.Printf("  %s\n", span.LocalEndpoint.ServiceName)
+				fmt.Printf("  %s %s %s\n", span.Timestamp, span.Name, span.ID)
 
-						}
+				if len(span.Annotations) > 0 {
+					fmt.Println("  annotations:")
+					for _, annotation := range span.Annotations {
+						fmt.Printf("    %s %s\n", annotation.Timestamp, annotation.Value)
+					}
+				}
+				if len(span.Tags) > 0 {
+					fmt.Println("  tags:")
+					for key, value := range span.Tags {
+						fmt.Printf("    %s=%s\n", key, value)
+					}
+				}
 
-						if span.RemoteEndpoint != nil {
-							fmt.Printf("  %s\n", span.RemoteEndpoint.ServiceName)
-						}
+			}
 
-						fmt.Printf("  %s %s %s\n", span.Timestamp, span.Name, span.ID.String())
+		}
+	}
+}
 
-						if len(span.Annotations) > 0 {
-							fmt.Println("  annotations:")
-							for _, annotation := range span.Annotations {
-								fmt.Printf("    %s\n", annotation)
-							}
-						}
-						if len(span.Tags) > 0 {
-							fmt.Println("  tags:")
-							for key, value := range span.Tags {
-								fmt.Printf("    %s=%s\n", key, value)
-							}
-						}
+// showRequest prints the line of a Knative Serving request
+func showRequest(span trace.Span) {
+	line := fmt.Sprintf("%s %s%s %s", span.Tags[trace.HTTPMethodTag], span.Tags[trace.HTTPHostTag], span.Tags[trace.HTTPPathTag], span.Tags[trace.HTTPStatusCodeTag])
+	if revision := trace.Revision(span); revision != "" {
+		line += " " + revision
+	} else {
+		line += " " + span.Service()
+	}
+	fmt.Println(line)
+}
 
-					}
+// showTrees displays the spans as trees
+func showTrees(spans []trace.Span, verbose bool, accept func(trace.Span) bool) {
+	for _, tree := range trace.Build(spans) {
+		if anySpan(tree, accept) {
+			output.PrintTree(os.Stdout, tree, verbose)
+		}
+	}
+}
 
-				}
-			}
+// showWaterfalls displays the spans as waterfall charts fitting in the terminal
+func showWaterfalls(spans []trace.Span, accept func(trace.Span) bool) {
+	width := output.TerminalWidth(os.Stdout)
+	for _, tree := range trace.Build(spans) {
+		if anySpan(tree, accept) {
+			output.PrintWaterfall(os.Stdout, tree, width)
 		}
 	}
-	return nil
 }
 
-func hasCloudEventTagId(span model.SpanModel) bool {
+func anySpan(tree *trace.Tree, accept func(trace.Span) bool) bool {
+	found := false
+	tree.Walk(func(node *trace.Node, depth int) {
+		found = found || accept(node.Span)
+	})
+	return found
+}
+
+func hasCloudEventTagId(span trace.Span) bool {
 	for key := range span.Tags {
 		if key == "cloudevents.id" {
 			return true
@@ -167,3 +384,31 @@ func hasCloudEventTagId(span model.SpanModel) bool {
 	}
 	return false
 }
+
+// summary counts the spans displayed during a session
+type summary struct {
+	start  time.Time
+	spans  int
+	traces map[string]bool
+}
+
+func newSummary(start time.Time) *summary {
+	return &summary{start: start, traces: make(map[string]bool)}
+}
+
+func (s *summary) add(spans []trace.Span, accept func(trace.Span) bool) {
+	for _, span := range spans {
+		if accept(span) {
+			s.spans++
+			s.traces[span.TraceID] = true
+		}
+	}
+}
+
+func (s *summary) print(w io.Writer, watermark time.Time) {
+	fmt.Fprintf(w, "\nDisplayed %d spans from %d traces in %s", s.spans, len(s.traces), time.Since(s.start).Round(time.Second))
+	if !watermark.IsZero() {
+		fmt.Fprintf(w, " (complete up to %s)", watermark.UTC().Format(time.RFC3339))
+	}
+	fmt.Fprintln(w)
+}
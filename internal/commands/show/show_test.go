@@ -0,0 +1,95 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package show
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"knative.dev/kn-plugin-trace/pkg/resolver"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+func TestWindow(t *testing.T) {
+	now := time.Date(2021, 11, 4, 10, 0, 0, 0, time.UTC)
+
+	flags := showFlags{since: "10m"}
+	since, until, err := flags.window(now)
+	assert.NilError(t, err)
+	assert.Equal(t, since, now.Add(-10*time.Minute))
+	assert.Equal(t, until, now)
+
+	flags = showFlags{since: "2021-11-04T08:00:00Z", until: "1h"}
+	since, until, err = flags.window(now)
+	assert.NilError(t, err)
+	assert.Equal(t, since, now.Add(-2*time.Hour))
+	assert.Equal(t, until, now.Add(-time.Hour))
+
+	flags = showFlags{since: "1h", until: "2h"}
+	_, _, err = flags.window(now)
+	assert.ErrorContains(t, err, "--since must be before --until")
+
+	flags = showFlags{since: "yesterday"}
+	_, _, err = flags.window(now)
+	assert.ErrorContains(t, err, "invalid --since")
+}
+
+func TestQuery(t *testing.T) {
+	flags := showFlags{source: "/apis/v1/namespaces/default/ping", eventType: "dev.knative.ping", minDuration: 5 * time.Millisecond, limit: 10}
+
+	query := flags.query()
+	assert.DeepEqual(t, query.Tags, map[string]string{
+		"cloudevents.source": "/apis/v1/namespaces/default/ping",
+		"cloudevents.type":   "dev.knative.ping",
+	})
+	assert.Equal(t, query.MinDuration, 5*time.Millisecond)
+	assert.Equal(t, query.Limit, 10)
+}
+
+func TestAcceptResources(t *testing.T) {
+	res := resolver.New()
+	ping := trace.Span{
+		Name:          "knative.dev",
+		LocalEndpoint: &trace.Endpoint{ServiceName: "pingsource-mt-adapter"},
+		Tags: map[string]string{
+			trace.CloudEventIDTag:     "1",
+			trace.CloudEventSourceTag: "/apis/v1/namespaces/default/pingsources/tick",
+		},
+	}
+	broker := trace.Span{Name: "broker:demo.default", Tags: map[string]string{trace.CloudEventIDTag: "1"}}
+	spans := []trace.Span{ping, broker}
+	res.Annotate(spans)
+
+	flags := showFlags{sourceObj: "tick"}
+	assert.Assert(t, flags.accept(res, spans[0]))
+	assert.Assert(t, !flags.accept(res, spans[1]))
+	assert.DeepEqual(t, flags.query().Tags, map[string]string{})
+
+	flags = showFlags{source: "/apis/v1/namespaces/default/pingsources/tick"}
+	assert.Assert(t, flags.accept(res, spans[0]))
+
+	// Relative sources are source attributes too
+	flags = showFlags{source: "tick"}
+	assert.Assert(t, !flags.accept(res, spans[0]))
+	assert.DeepEqual(t, flags.query().Tags, map[string]string{trace.CloudEventSourceTag: "tick"})
+
+	flags = showFlags{broker: "default/demo"}
+	assert.Assert(t, !flags.accept(res, spans[0]))
+	assert.Assert(t, flags.accept(res, spans[1]))
+
+	flags = showFlags{trigger: "default/display"}
+	assert.DeepEqual(t, flags.query().Tags, map[string]string{trace.MessagingDestinationTag: "trigger:display.default"})
+}
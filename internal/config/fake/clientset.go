@@ -0,0 +1,97 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fake provides a Kubernetes clientset supporting the writes of tracing configurations
+package fake
+
+import (
+	"encoding/json"
+	"errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// NewClientset creates a clientset holding the given objects. Server-side
+// apply of ConfigMaps, which the fake object tracker does not support, is
+// emulated by merging the data and annotations of the applied ConfigMap, and
+// removing the ones the previous apply set and this one does not. All the
+// applies are assumed to come from the same field manager.
+func NewClientset(objects ...runtime.Object) *fake.Clientset {
+	client := fake.NewSimpleClientset(objects...)
+	applied := make(map[string]*corev1.ConfigMap)
+	client.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+
+		var cfg corev1.ConfigMap
+		if err := json.Unmarshal(patch.GetPatch(), &cfg); err != nil {
+			return true, nil, err
+		}
+		cfg.TypeMeta = corev1.ConfigMap{}.TypeMeta
+		key := patch.GetNamespace() + "/" + patch.GetName()
+
+		tracker := client.Tracker()
+		obj, err := tracker.Get(action.GetResource(), patch.GetNamespace(), patch.GetName())
+		if apierrors.IsNotFound(err) {
+			cfg.Namespace = patch.GetNamespace()
+			cfg.ResourceVersion = ""
+			applied[key] = cfg.DeepCopy()
+			return true, &cfg, tracker.Create(action.GetResource(), &cfg, patch.GetNamespace())
+		}
+		if err != nil {
+			return true, nil, err
+		}
+
+		cm := obj.(*corev1.ConfigMap).DeepCopy()
+		if cfg.ResourceVersion != "" && cfg.ResourceVersion != cm.ResourceVersion {
+			return true, nil, apierrors.NewConflict(action.GetResource().GroupResource(), cm.Name, errors.New("the object has been modified"))
+		}
+
+		if previous, ok := applied[key]; ok {
+			for k := range previous.Data {
+				if _, ok := cfg.Data[k]; !ok {
+					delete(cm.Data, k)
+				}
+			}
+			for k := range previous.Annotations {
+				if _, ok := cfg.Annotations[k]; !ok {
+					delete(cm.Annotations, k)
+				}
+			}
+		}
+		applied[key] = cfg.DeepCopy()
+
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		for k, value := range cfg.Data {
+			cm.Data[k] = value
+		}
+		for k, value := range cfg.Annotations {
+			if cm.Annotations == nil {
+				cm.Annotations = map[string]string{}
+			}
+			cm.Annotations[k] = value
+		}
+		return true, cm, tracker.Update(action.GetResource(), cm, patch.GetNamespace())
+	})
+	return client
+}
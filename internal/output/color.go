@@ -16,6 +16,9 @@
 package output
 
 import (
+	"fmt"
+	"io"
+
 	"github.com/fatih/color"
 )
 
@@ -30,3 +33,9 @@ func Error() {
 func Warning() {
 	color.New(color.FgYellow).PrintFunc()("⚠ ")
 }
+
+// Warn writes a warning message to w
+func Warn(w io.Writer, format string, a ...interface{}) {
+	color.New(color.FgYellow).Fprint(w, "⚠ ")
+	fmt.Fprintf(w, format+"\n", a...)
+}
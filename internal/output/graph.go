@@ -0,0 +1,189 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+// Dependency graph formats
+const (
+	GraphASCII   = "ascii"
+	GraphDOT     = "dot"
+	GraphMermaid = "mermaid"
+)
+
+// GraphFormats lists the supported dependency graph formats
+var GraphFormats = []string{GraphASCII, GraphDOT, GraphMermaid}
+
+// PrintGraph writes the dependencies between services in the given format.
+// components maps services to their role in the path of CloudEvents, and may be nil.
+func PrintGraph(w io.Writer, format string, deps []trace.Dependency, components map[string]string) error {
+	deps = append([]trace.Dependency(nil), deps...)
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].Parent != deps[j].Parent {
+			return deps[i].Parent < deps[j].Parent
+		}
+		return deps[i].Child < deps[j].Child
+	})
+
+	switch format {
+	case GraphASCII:
+		printGraphASCII(w, deps, components)
+	case GraphDOT:
+		printGraphDOT(w, deps, components)
+	case GraphMermaid:
+		printGraphMermaid(w, deps, components)
+	default:
+		return fmt.Errorf("invalid graph format %q. Must be one of: %s", format, strings.Join(GraphFormats, ", "))
+	}
+	return nil
+}
+
+// printGraphASCII writes each service followed by the services it calls
+func printGraphASCII(w io.Writer, deps []trace.Dependency, components map[string]string) {
+	if len(deps) == 0 {
+		fmt.Fprintln(w, "No dependencies found.")
+		return
+	}
+
+	byParent := make(map[string][]trace.Dependency)
+	for _, dep := range deps {
+		byParent[dep.Parent] = append(byParent[dep.Parent], dep)
+	}
+
+	service := color.New(color.FgCyan).SprintFunc()
+	errors := color.New(color.FgRed).SprintFunc()
+	printed := false
+	for _, parent := range sortedServices(deps) {
+		children := byParent[parent]
+		if len(children) == 0 {
+			continue
+		}
+		if printed {
+			fmt.Fprintln(w)
+		}
+		printed = true
+
+		fmt.Fprintln(w, service(parent)+describeComponent(components[parent]))
+		for j, dep := range children {
+			branch := "├─▶ "
+			if j == len(children)-1 {
+				branch = "└─▶ "
+			}
+
+			calls := fmt.Sprintf("%d %s", dep.CallCount, plural(dep.CallCount, "call"))
+			if dep.ErrorCount > 0 {
+				calls += ", " + errors(fmt.Sprintf("%d %s", dep.ErrorCount, plural(dep.ErrorCount, "error")))
+			}
+			fmt.Fprintf(w, "%s%s%s  %s\n", branch, service(dep.Child), describeComponent(components[dep.Child]), calls)
+		}
+	}
+}
+
+// printGraphDOT writes the graph in the Graphviz DOT language
+func printGraphDOT(w io.Writer, deps []trace.Dependency, components map[string]string) {
+	fmt.Fprintln(w, "digraph dependencies {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=box];")
+	for _, svc := range sortedServices(deps) {
+		label := svc
+		if component := components[svc]; component != "" {
+			label += "\n(" + component + ")"
+		}
+		fmt.Fprintf(w, "  %s [label=%s];\n", strconv.Quote(svc), strconv.Quote(label))
+	}
+	for _, dep := range deps {
+		attrs := fmt.Sprintf("label=%s", strconv.Quote(edgeLabel(dep)))
+		if dep.ErrorCount > 0 {
+			attrs += ", color=red, fontcolor=red"
+		}
+		fmt.Fprintf(w, "  %s -> %s [%s];\n", strconv.Quote(dep.Parent), strconv.Quote(dep.Child), attrs)
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// printGraphMermaid writes the graph as a Mermaid flowchart
+func printGraphMermaid(w io.Writer, deps []trace.Dependency, components map[string]string) {
+	fmt.Fprintln(w, "graph LR")
+
+	// Service names are not valid Mermaid identifiers
+	ids := make(map[string]string)
+	for i, svc := range sortedServices(deps) {
+		ids[svc] = "n" + strconv.Itoa(i)
+
+		label := svc
+		if component := components[svc]; component != "" {
+			label += " (" + component + ")"
+		}
+		fmt.Fprintf(w, "  %s[\"%s\"]\n", ids[svc], strings.ReplaceAll(label, `"`, "#quot;"))
+	}
+
+	var failed []int
+	for i, dep := range deps {
+		fmt.Fprintf(w, "  %s -->|%s| %s\n", ids[dep.Parent], edgeLabel(dep), ids[dep.Child])
+		if dep.ErrorCount > 0 {
+			failed = append(failed, i)
+		}
+	}
+	for _, i := range failed {
+		fmt.Fprintf(w, "  linkStyle %d stroke:red\n", i)
+	}
+}
+
+// sortedServices returns the services of the dependencies in alphabetical order
+func sortedServices(deps []trace.Dependency) []string {
+	seen := make(map[string]bool)
+	var services []string
+	for _, dep := range deps {
+		for _, svc := range []string{dep.Parent, dep.Child} {
+			if !seen[svc] {
+				seen[svc] = true
+				services = append(services, svc)
+			}
+		}
+	}
+	sort.Strings(services)
+	return services
+}
+
+func edgeLabel(dep trace.Dependency) string {
+	if dep.ErrorCount > 0 {
+		return fmt.Sprintf("%d, %d %s", dep.CallCount, dep.ErrorCount, plural(dep.ErrorCount, "error"))
+	}
+	return strconv.FormatInt(dep.CallCount, 10)
+}
+
+func describeComponent(component string) string {
+	if component == "" {
+		return ""
+	}
+	return " (" + component + ")"
+}
+
+func plural(n int64, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
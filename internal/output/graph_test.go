@@ -0,0 +1,79 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+	"gotest.tools/v3/assert"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+var deps = []trace.Dependency{
+	{Parent: "broker-ingress", Child: "broker-filter", CallCount: 12},
+	{Parent: "broker-filter", Child: "event-display", CallCount: 12, ErrorCount: 1},
+}
+
+var components = map[string]string{
+	"broker-ingress": trace.ComponentBroker,
+	"broker-filter":  trace.ComponentTrigger,
+}
+
+func TestPrintGraphASCII(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	out := new(bytes.Buffer)
+	assert.NilError(t, PrintGraph(out, GraphASCII, deps, components))
+	assert.Equal(t, out.String(), `broker-filter (trigger)
+└─▶ event-display  12 calls, 1 error
+
+broker-ingress (broker)
+└─▶ broker-filter (trigger)  12 calls
+`)
+}
+
+func TestPrintGraphDOT(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NilError(t, PrintGraph(out, GraphDOT, deps, components))
+	assert.Equal(t, out.String(), `digraph dependencies {
+  rankdir=LR;
+  node [shape=box];
+  "broker-filter" [label="broker-filter\n(trigger)"];
+  "broker-ingress" [label="broker-ingress\n(broker)"];
+  "event-display" [label="event-display"];
+  "broker-filter" -> "event-display" [label="12, 1 error", color=red, fontcolor=red];
+  "broker-ingress" -> "broker-filter" [label="12"];
+}
+`)
+}
+
+func TestPrintGraphMermaid(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NilError(t, PrintGraph(out, GraphMermaid, deps, nil))
+	assert.Equal(t, out.String(), `graph LR
+  n0["broker-filter"]
+  n1["broker-ingress"]
+  n2["event-display"]
+  n0 -->|12, 1 error| n2
+  n1 -->|12| n0
+  linkStyle 0 stroke:red
+`)
+
+	assert.ErrorContains(t, PrintGraph(out, "svg", deps, nil), "invalid graph format")
+}
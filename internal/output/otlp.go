@@ -0,0 +1,185 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+// OTLPJSONFormat is the output format for the OpenTelemetry protocol JSON encoding
+const OTLPJSONFormat = "otlp-json"
+
+// OTLP JSON encoding, as defined by https://github.com/open-telemetry/opentelemetry-proto
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Events            []otlpEvent     `json:"events,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string `json:"timeUnixNano"`
+	Name         string `json:"name"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// OTLP span kinds and status codes
+const (
+	otlpKindInternal = 1
+	otlpKindServer   = 2
+	otlpKindClient   = 3
+	otlpKindProducer = 4
+	otlpKindConsumer = 5
+
+	otlpStatusError = 2
+)
+
+// PrintOTLP writes the given spans in the OpenTelemetry protocol JSON encoding.
+// Spans are grouped by service.
+func PrintOTLP(w io.Writer, spans []trace.Span) error {
+	byService := make(map[string][]otlpSpan)
+	for _, span := range spans {
+		service := span.Service()
+		byService[service] = append(byService[service], toOTLPSpan(span))
+	}
+
+	services := make([]string, 0, len(byService))
+	for service := range byService {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	traces := otlpTraces{ResourceSpans: make([]otlpResourceSpans, 0, len(services))}
+	for _, service := range services {
+		traces.ResourceSpans = append(traces.ResourceSpans, otlpResourceSpans{
+			Resource: otlpResource{
+				Attributes: []otlpAttribute{stringAttribute("service.name", service)},
+			},
+			ScopeSpans: []otlpScopeSpans{{Spans: byService[service]}},
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(traces)
+}
+
+func toOTLPSpan(span trace.Span) otlpSpan {
+	s := otlpSpan{
+		TraceID:           padID(span.TraceID, 32),
+		SpanID:            padID(span.ID, 16),
+		Name:              span.Name,
+		Kind:              otlpKind(span.Kind),
+		StartTimeUnixNano: unixNano(span.Timestamp.UnixNano()),
+		EndTimeUnixNano:   unixNano(span.Timestamp.Add(span.Duration).UnixNano()),
+	}
+	if span.ParentID != "" {
+		s.ParentSpanID = padID(span.ParentID, 16)
+	}
+
+	keys := make([]string, 0, len(span.Tags))
+	for key := range span.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s.Attributes = append(s.Attributes, stringAttribute(key, span.Tags[key]))
+	}
+
+	for _, annotation := range span.Annotations {
+		s.Events = append(s.Events, otlpEvent{TimeUnixNano: unixNano(annotation.Timestamp.UnixNano()), Name: annotation.Value})
+	}
+
+	if msg, ok := span.Tags[trace.ErrorTag]; ok {
+		s.Status = otlpStatus{Code: otlpStatusError, Message: msg}
+	}
+	return s
+}
+
+func otlpKind(kind trace.Kind) int {
+	switch kind {
+	case trace.KindServer:
+		return otlpKindServer
+	case trace.KindClient:
+		return otlpKindClient
+	case trace.KindProducer:
+		return otlpKindProducer
+	case trace.KindConsumer:
+		return otlpKindConsumer
+	default:
+		// Zipkin spans without kind are local spans
+		return otlpKindInternal
+	}
+}
+
+func stringAttribute(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{StringValue: value}}
+}
+
+// padID left-pads hex-encoded IDs with zeros, as OTLP IDs have a fixed size
+func padID(id string, size int) string {
+	if len(id) >= size {
+		return id
+	}
+	return strings.Repeat("0", size-len(id)) + id
+}
+
+// unixNano encodes 64-bit integers as strings, as required by the protobuf JSON mapping
+func unixNano(ns int64) string {
+	return strconv.FormatInt(ns, 10)
+}
@@ -0,0 +1,60 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+func TestPrintOTLPPadsIDs(t *testing.T) {
+	spans := []trace.Span{{
+		TraceID:   "abc",
+		ID:        "1",
+		Name:      "root",
+		Timestamp: time.Unix(1636000000, 0),
+	}, {
+		TraceID:   "abc",
+		ID:        "2",
+		ParentID:  "1",
+		Name:      "child",
+		Timestamp: time.Unix(1636000000, 0),
+	}}
+
+	out := new(bytes.Buffer)
+	assert.NilError(t, PrintOTLP(out, spans))
+
+	var traces otlpTraces
+	assert.NilError(t, json.Unmarshal(out.Bytes(), &traces))
+	printed := traces.ResourceSpans[0].ScopeSpans[0].Spans
+	assert.Equal(t, len(printed), 2)
+
+	for _, span := range printed {
+		assert.Equal(t, span.TraceID, "00000000000000000000000000000abc")
+		switch span.Name {
+		case "root":
+			assert.Equal(t, span.SpanID, "0000000000000001")
+			assert.Equal(t, span.ParentSpanID, "")
+		case "child":
+			assert.Equal(t, span.SpanID, "0000000000000002")
+			assert.Equal(t, span.ParentSpanID, "0000000000000001")
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+
+	knflags "knative.dev/client/pkg/kn/commands/flags"
+)
+
+// PrintFlags are the flags selecting a machine-readable output format for spans
+type PrintFlags struct {
+	listFlags *knflags.ListPrintFlags
+}
+
+// AddFlags adds the --output flag and its companions to the command
+func (p *PrintFlags) AddFlags(cmd *cobra.Command) {
+	// Only the machine-readable formats are supported. Views cover human-readable output.
+	p.listFlags = knflags.NewListPrintFlags(nil)
+	p.listFlags.GenericPrintFlags.AddFlags(cmd)
+	outputFlag := cmd.Flags().Lookup("output")
+	outputFlag.Usage = fmt.Sprintf("Output format. One of: %s.", strings.Join(p.allowedFormats(), "|"))
+}
+
+func (p *PrintFlags) allowedFormats() []string {
+	return append(p.listFlags.GenericPrintFlags.AllowedFormats(), OTLPJSONFormat)
+}
+
+// Structured returns true when a machine-readable output format has been requested
+func (p *PrintFlags) Structured() bool {
+	return p.listFlags.GenericPrintFlags.OutputFlagSpecified()
+}
+
+// Validate checks the requested output format is supported
+func (p *PrintFlags) Validate() error {
+	if p.Structured() && p.format() != OTLPJSONFormat {
+		if _, err := p.listFlags.ToPrinter(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *PrintFlags) format() string {
+	return *p.listFlags.GenericPrintFlags.OutputFormat
+}
+
+// Print prints the accepted spans in the requested format, ordered as in their trace trees.
+// A nil accept function accepts all spans.
+func (p *PrintFlags) Print(w io.Writer, spans []trace.Span, accept func(trace.Span) bool) error {
+	var ordered []trace.Span
+	for _, tree := range trace.Build(spans) {
+		tree.Walk(func(node *trace.Node, depth int) {
+			if accept == nil || accept(node.Span) {
+				ordered = append(ordered, node.Span)
+			}
+		})
+	}
+
+	if p.format() == OTLPJSONFormat {
+		return PrintOTLP(w, ordered)
+	}
+	return p.listFlags.Print(NewSpanList(ordered), w)
+}
@@ -0,0 +1,62 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gotest.tools/v3/assert"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+func TestPrintFlags(t *testing.T) {
+	spans := []trace.Span{{
+		TraceID:   "0000000000000abc",
+		ID:        "0000000000000002",
+		ParentID:  "0000000000000001",
+		Name:      "child",
+		Kind:      trace.KindServer,
+		Timestamp: time.Unix(1636000001, 0),
+	}, {
+		TraceID:   "0000000000000abc",
+		ID:        "0000000000000001",
+		Name:      "root",
+		Kind:      trace.KindServer,
+		Timestamp: time.Unix(1636000000, 0),
+	}}
+
+	cmd := &cobra.Command{}
+	var printFlags PrintFlags
+	printFlags.AddFlags(cmd)
+	assert.Assert(t, !printFlags.Structured())
+
+	assert.NilError(t, cmd.Flags().Set("output", "jsonpath={.items[*].name}"))
+	assert.Assert(t, printFlags.Structured())
+	assert.NilError(t, printFlags.Validate())
+
+	out := new(bytes.Buffer)
+	assert.NilError(t, printFlags.Print(out, spans, nil))
+	assert.Equal(t, out.String(), "root child")
+
+	out.Reset()
+	assert.NilError(t, printFlags.Print(out, spans, func(span trace.Span) bool { return span.Name == "child" }))
+	assert.Equal(t, out.String(), "child")
+
+	assert.NilError(t, cmd.Flags().Set("output", "yaml-ish"))
+	assert.ErrorContains(t, printFlags.Validate(), "yaml-ish")
+}
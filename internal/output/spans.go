@@ -0,0 +1,197 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package output
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+// APIVersion is the version of the printed span objects
+const APIVersion = "trace.knative.dev/v1alpha1"
+
+// SpanList is the printable representation of a list of spans
+type SpanList struct {
+	metav1.TypeMeta `json:",inline"`
+	Items           []Span `json:"items"`
+}
+
+// Span is the printable representation of a span
+type Span struct {
+	metav1.TypeMeta `json:",inline"`
+
+	TraceID  string `json:"traceId"`
+	ID       string `json:"id"`
+	ParentID string `json:"parentId,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Kind     string `json:"kind,omitempty"`
+	Shared   bool   `json:"shared,omitempty"`
+
+	Timestamp time.Time `json:"timestamp"`
+	// Duration in microseconds
+	Duration int64 `json:"duration"`
+
+	LocalEndpoint  *Endpoint `json:"localEndpoint,omitempty"`
+	RemoteEndpoint *Endpoint `json:"remoteEndpoint,omitempty"`
+
+	CloudEvent *CloudEvent `json:"cloudEvent,omitempty"`
+
+	// Resource is the Knative object the span belongs to
+	Resource *Resource `json:"resource,omitempty"`
+
+	Annotations []Annotation      `json:"annotations,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+// Endpoint is the network context of a span
+type Endpoint struct {
+	ServiceName string `json:"serviceName,omitempty"`
+	IPv4        string `json:"ipv4,omitempty"`
+	IPv6        string `json:"ipv6,omitempty"`
+	Port        uint16 `json:"port,omitempty"`
+}
+
+// Annotation is an event that occurred during a span
+type Annotation struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     string    `json:"value"`
+}
+
+// Resource identifies a Kubernetes object
+type Resource struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// CloudEvent holds the attributes of the CloudEvent a span is about
+type CloudEvent struct {
+	ID          string `json:"id,omitempty"`
+	Source      string `json:"source,omitempty"`
+	Type        string `json:"type,omitempty"`
+	SpecVersion string `json:"specversion,omitempty"`
+	Subject     string `json:"subject,omitempty"`
+}
+
+// NewSpanList converts spans to their printable representation
+func NewSpanList(spans []trace.Span) *SpanList {
+	list := &SpanList{
+		TypeMeta: metav1.TypeMeta{APIVersion: APIVersion, Kind: "SpanList"},
+		Items:    make([]Span, 0, len(spans)),
+	}
+	for _, span := range spans {
+		list.Items = append(list.Items, NewSpan(span))
+	}
+	return list
+}
+
+// NewSpan converts a span to its printable representation
+func NewSpan(span trace.Span) Span {
+	s := Span{
+		TypeMeta:       metav1.TypeMeta{APIVersion: APIVersion, Kind: "Span"},
+		TraceID:        span.TraceID,
+		ID:             span.ID,
+		ParentID:       span.ParentID,
+		Name:           span.Name,
+		Kind:           string(span.Kind),
+		Shared:         span.Shared,
+		Timestamp:      span.Timestamp.UTC(),
+		Duration:       span.Duration.Microseconds(),
+		LocalEndpoint:  newEndpoint(span.LocalEndpoint),
+		RemoteEndpoint: newEndpoint(span.RemoteEndpoint),
+		Tags:           span.Tags,
+	}
+
+	if span.Resource != nil {
+		r := Resource(*span.Resource)
+		s.Resource = &r
+	}
+
+	for _, annotation := range span.Annotations {
+		s.Annotations = append(s.Annotations, Annotation{Timestamp: annotation.Timestamp.UTC(), Value: annotation.Value})
+	}
+
+	if id, ok := span.Tags[trace.CloudEventIDTag]; ok {
+		s.CloudEvent = &CloudEvent{
+			ID:          id,
+			Source:      span.Tags[trace.CloudEventSourceTag],
+			Type:        span.Tags[trace.CloudEventTypeTag],
+			SpecVersion: span.Tags[trace.CloudEventSpecVersionTag],
+			Subject:     span.Tags[trace.CloudEventSubjectTag],
+		}
+	}
+	return s
+}
+
+func newEndpoint(endpoint *trace.Endpoint) *Endpoint {
+	if endpoint == nil {
+		return nil
+	}
+
+	e := Endpoint(*endpoint)
+	return &e
+}
+
+// DeepCopyObject implements runtime.Object
+func (l *SpanList) DeepCopyObject() runtime.Object {
+	out := &SpanList{TypeMeta: l.TypeMeta}
+	if l.Items != nil {
+		out.Items = make([]Span, len(l.Items))
+		for i := range l.Items {
+			out.Items[i] = *l.Items[i].deepCopy()
+		}
+	}
+	return out
+}
+
+// DeepCopyObject implements runtime.Object
+func (s *Span) DeepCopyObject() runtime.Object {
+	return s.deepCopy()
+}
+
+func (s *Span) deepCopy() *Span {
+	out := *s
+	if s.LocalEndpoint != nil {
+		e := *s.LocalEndpoint
+		out.LocalEndpoint = &e
+	}
+	if s.RemoteEndpoint != nil {
+		e := *s.RemoteEndpoint
+		out.RemoteEndpoint = &e
+	}
+	if s.CloudEvent != nil {
+		ce := *s.CloudEvent
+		out.CloudEvent = &ce
+	}
+	if s.Resource != nil {
+		r := *s.Resource
+		out.Resource = &r
+	}
+	if s.Annotations != nil {
+		out.Annotations = append([]Annotation(nil), s.Annotations...)
+	}
+	if s.Tags != nil {
+		out.Tags = make(map[string]string, len(s.Tags))
+		for key, value := range s.Tags {
+			out.Tags[key] = value
+		}
+	}
+	return &out
+}
@@ -0,0 +1,56 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gotest.tools/v3/assert"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+
+	knflags "knative.dev/client/pkg/kn/commands/flags"
+)
+
+func TestPrintSpanList(t *testing.T) {
+	spans := []trace.Span{{
+		TraceID:   "0000000000000abc",
+		ID:        "0000000000000002",
+		ParentID:  "0000000000000001",
+		Name:      "broker:default.demo",
+		Kind:      trace.KindServer,
+		Timestamp: time.Unix(1636000000, 0),
+		Duration:  1500 * time.Microsecond,
+		LocalEndpoint: &trace.Endpoint{
+			ServiceName: "broker-ingress.knative-eventing",
+		},
+		Tags: map[string]string{
+			"cloudevents.id":     "1234",
+			"cloudevents.source": "/demo",
+			"cloudevents.type":   "dev.knative.demo",
+		},
+	}}
+
+	cmd := &cobra.Command{}
+	printFlags := knflags.NewListPrintFlags(nil)
+	printFlags.GenericPrintFlags.AddFlags(cmd)
+	assert.NilError(t, cmd.Flags().Set("output", "jsonpath={.items[0].traceId} {.items[0].parentId} {.items[0].duration} {.items[0].cloudEvent.type}"))
+
+	out := new(bytes.Buffer)
+	assert.NilError(t, printFlags.Print(NewSpanList(spans), out))
+	assert.Equal(t, out.String(), "0000000000000abc 0000000000000001 1500 dev.knative.demo")
+}
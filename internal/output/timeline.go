@@ -0,0 +1,71 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+// PrintTimeline writes the spans of the given trace in start order, one per line,
+// with their offset from the start of the trace. Nesting is shown by indenting span names.
+// When verbose is true, span tags are displayed below each span.
+func PrintTimeline(w io.Writer, tree *trace.Tree, verbose bool) {
+	printHeader(w, tree)
+
+	type entry struct {
+		node  *trace.Node
+		depth int
+	}
+	var entries []entry
+	tree.Walk(func(node *trace.Node, depth int) {
+		entries = append(entries, entry{node: node, depth: depth})
+	})
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].node.Span.Timestamp.Before(entries[j].node.Span.Timestamp)
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "OFFSET\tDURATION\tSERVICE\tSPAN")
+	for _, e := range entries {
+		span := e.node.Span
+
+		name := span.Name
+		if span.Failed() {
+			name = color.New(color.FgRed).Sprint(name)
+		}
+		fmt.Fprintf(tw, "+%s\t%s\t%s\t%s%s\n", FormatDuration(tree.Offset(span)), FormatDuration(span.Duration), span.Service(), strings.Repeat("  ", e.depth), name)
+
+		if verbose {
+			keys := make([]string, 0, len(span.Tags))
+			for key := range span.Tags {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			for _, key := range keys {
+				fmt.Fprintf(tw, "\t\t\t%s  %s=%s\n", strings.Repeat("  ", e.depth), key, span.Tags[key])
+			}
+		}
+	}
+	tw.Flush()
+}
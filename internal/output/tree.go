@@ -0,0 +1,111 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+// PrintTree writes the given trace as an indented tree, one span per line.
+// When verbose is true, span tags are displayed below each span.
+func PrintTree(w io.Writer, tree *trace.Tree, verbose bool) {
+	printHeader(w, tree)
+
+	printNodes(w, tree, tree.Roots, "", verbose)
+}
+
+// printHeader writes the trace ID and duration
+func printHeader(w io.Writer, tree *trace.Tree) {
+	fmt.Fprintf(w, "%s %s %s\n", color.New(color.Bold).Sprint("trace"), tree.TraceID, FormatDuration(tree.Duration))
+}
+
+func printNodes(w io.Writer, tree *trace.Tree, nodes []*trace.Node, prefix string, verbose bool) {
+	for i, node := range nodes {
+		last := i == len(nodes)-1
+
+		branch, indent := "├── ", "│   "
+		if last {
+			branch, indent = "└── ", "    "
+		}
+
+		fmt.Fprintf(w, "%s%s%s\n", prefix, branch, describeSpan(tree, node.Span))
+
+		if verbose {
+			printTags(w, prefix+indent, node)
+		}
+
+		printNodes(w, tree, node.Children, prefix+indent, verbose)
+	}
+}
+
+func describeSpan(tree *trace.Tree, span trace.Span) string {
+	faint := color.New(color.Faint).SprintFunc()
+
+	var b strings.Builder
+	if service := span.Service(); service != "" {
+		b.WriteString(color.New(color.FgCyan).Sprint(service))
+		b.WriteString(" ")
+	}
+	b.WriteString(span.Name)
+
+	if id, ok := span.Tags["cloudevents.id"]; ok {
+		fmt.Fprintf(&b, " [%s %s %s]", span.Tags["cloudevents.source"], id, span.Tags["cloudevents.type"])
+	}
+
+	if span.Resource != nil {
+		fmt.Fprintf(&b, " %s", color.New(color.FgMagenta).Sprint(span.Resource))
+	}
+
+	fmt.Fprintf(&b, " %s %s", faint("+"+FormatDuration(tree.Offset(span))), FormatDuration(span.Duration))
+	return b.String()
+}
+
+func printTags(w io.Writer, prefix string, node *trace.Node) {
+	childPrefix := prefix + "    "
+	if len(node.Children) > 0 {
+		childPrefix = prefix + "│   "
+	}
+
+	keys := make([]string, 0, len(node.Span.Tags))
+	for key := range node.Span.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s%s=%s\n", childPrefix, key, node.Span.Tags[key])
+	}
+}
+
+// FormatDuration rounds durations to a precision suitable for traces
+func FormatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	default:
+		return d.String()
+	}
+}
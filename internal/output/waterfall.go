@@ -0,0 +1,170 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"golang.org/x/term"
+
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+const (
+	// defaultWidth is the width used when the terminal width is unknown
+	defaultWidth = 120
+
+	// minBarWidth is the minimum number of columns used to draw span bars
+	minBarWidth = 20
+
+	// durationWidth is the number of columns reserved for span durations
+	durationWidth = 10
+)
+
+// servicePalette holds the colors of service bars. Red is reserved for errors.
+var servicePalette = []color.Attribute{
+	color.FgCyan,
+	color.FgGreen,
+	color.FgYellow,
+	color.FgBlue,
+	color.FgMagenta,
+	color.FgHiCyan,
+	color.FgHiGreen,
+	color.FgHiBlue,
+	color.FgHiMagenta,
+}
+
+// TerminalWidth returns the width of the terminal f is attached to.
+// The COLUMNS environment variable is used when f is not a terminal.
+func TerminalWidth(f *os.File) int {
+	if width, _, err := term.GetSize(int(f.Fd())); err == nil && width > 0 {
+		return width
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return defaultWidth
+}
+
+// PrintWaterfall writes the given trace as a waterfall chart fitting in width
+// columns. Each span is drawn as a bar positioned and sized according to its
+// start offset and duration, colored by service. Failed spans are drawn in red.
+func PrintWaterfall(w io.Writer, tree *trace.Tree, width int) {
+	printHeader(w, tree)
+
+	type row struct {
+		label string
+		span  trace.Span
+	}
+	var rows []row
+	labelWidth := 0
+	tree.Walk(func(node *trace.Node, depth int) {
+		label := strings.Repeat("  ", depth) + node.Span.Name
+		if service := node.Span.Service(); service != "" {
+			label = strings.Repeat("  ", depth) + service + " " + node.Span.Name
+		}
+		rows = append(rows, row{label: label, span: node.Span})
+		if n := len([]rune(label)); n > labelWidth {
+			labelWidth = n
+		}
+	})
+
+	// Leave at least minBarWidth columns to the bars, labels are truncated if needed
+	barWidth := width - labelWidth - durationWidth - 2
+	if barWidth < minBarWidth {
+		barWidth = minBarWidth
+		labelWidth = width - barWidth - durationWidth - 2
+		if labelWidth < 10 {
+			labelWidth = 10
+		}
+	}
+
+	faint := color.New(color.Faint).SprintFunc()
+	gap := barWidth - len("+0") - len("+"+FormatDuration(tree.Duration))
+	if gap < 1 {
+		gap = 1
+	}
+	scale := "+0" + strings.Repeat(" ", gap) + "+" + FormatDuration(tree.Duration)
+	fmt.Fprintf(w, "%s %s\n", strings.Repeat(" ", labelWidth), faint(scale))
+
+	for _, r := range rows {
+		start, length := barExtent(tree.Offset(r.span), r.span.Duration, tree.Duration, barWidth)
+
+		c := serviceColor(r.span.Service())
+		duration := FormatDuration(r.span.Duration)
+		if r.span.Failed() {
+			c = color.New(color.FgRed)
+			duration += " ✗"
+		}
+
+		fmt.Fprintf(w, "%s %s%s%s %s\n",
+			pad(truncate(r.label, labelWidth), labelWidth),
+			strings.Repeat(" ", start),
+			c.Sprint(strings.Repeat("█", length)),
+			strings.Repeat(" ", barWidth-start-length),
+			duration)
+	}
+}
+
+// barExtent returns the first column and the number of columns of a span bar.
+// Bars are at least one column wide so that instantaneous spans remain visible.
+func barExtent(offset, duration, total time.Duration, width int) (start int, length int) {
+	if total <= 0 {
+		return 0, 1
+	}
+
+	start = int(int64(offset) * int64(width) / int64(total))
+	if start >= width {
+		start = width - 1
+	}
+	if start < 0 {
+		start = 0
+	}
+
+	length = int(int64(duration) * int64(width) / int64(total))
+	if length < 1 {
+		length = 1
+	}
+	if start+length > width {
+		length = width - start
+	}
+	return start, length
+}
+
+// serviceColor returns a color which is stable for a given service
+func serviceColor(service string) *color.Color {
+	h := fnv.New32a()
+	h.Write([]byte(service))
+	return color.New(servicePalette[h.Sum32()%uint32(len(servicePalette))])
+}
+
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+func pad(s string, width int) string {
+	return s + strings.Repeat(" ", width-len([]rune(s)))
+}
@@ -0,0 +1,72 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"gotest.tools/v3/assert"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+func TestPrintWaterfall(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	start := time.Unix(1636000000, 0)
+	spans := []trace.Span{{
+		TraceID:       "abc",
+		ID:            "1",
+		Name:          "send",
+		Timestamp:     start,
+		Duration:      40 * time.Millisecond,
+		LocalEndpoint: &trace.Endpoint{ServiceName: "ping"},
+	}, {
+		TraceID:       "abc",
+		ID:            "2",
+		ParentID:      "1",
+		Name:          "receive",
+		Timestamp:     start.Add(20 * time.Millisecond),
+		Duration:      10 * time.Millisecond,
+		LocalEndpoint: &trace.Endpoint{ServiceName: "sink"},
+		Tags:          map[string]string{trace.ErrorTag: "500"},
+	}}
+
+	out := new(bytes.Buffer)
+	PrintWaterfall(out, trace.Build(spans)[0], 50)
+
+	assert.Equal(t, out.String(), strings.Join([]string{
+		"trace abc 40ms",
+		"               +0                 +40ms",
+		"ping send      ████████████████████████ 40ms",
+		"  sink receive             ██████       10ms ✗",
+		"",
+	}, "\n"))
+}
+
+func TestBarExtent(t *testing.T) {
+	start, length := barExtent(0, 0, 0, 20)
+	assert.Equal(t, start, 0)
+	assert.Equal(t, length, 1)
+
+	start, length = barExtent(time.Second, time.Microsecond, time.Second, 20)
+	assert.Equal(t, start, 19)
+	assert.Equal(t, length, 1)
+}
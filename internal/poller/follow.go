@@ -0,0 +1,105 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package poller
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"knative.dev/kn-plugin-trace/pkg/proxy"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+// maxBackoff is the maximum delay between polls after failures
+const maxBackoff = 30 * time.Second
+
+// Schedule controls when Follow polls
+type Schedule struct {
+	// Interval is how long to wait between polls
+	Interval time.Duration
+
+	// Delay is how long before now spans are searched, to let them be
+	// reported and absorb clock skew with the cluster
+	Delay time.Duration
+}
+
+// Result is the outcome of a poll
+type Result struct {
+	Spans []trace.Span
+	Err   error
+
+	// Next is how long until the next poll. Failed polls are retried with
+	// an exponential backoff honoring Retry-After.
+	Next time.Duration
+}
+
+// Follow polls the spans matching the query between since and until, then
+// keeps searching again from the watermark, never before since, until the
+// schedule delay before now. Transient errors are retried.
+//
+// emit receives the result of each poll. Follow returns when emit returns
+// false or the context is done.
+func (p *Poller) Follow(ctx context.Context, query trace.Query, since, until time.Time, schedule Schedule, emit func(Result) bool) {
+	start := since
+	backoff := newBackoff(schedule.Interval)
+	for {
+		spans, err := p.Poll(ctx, query, since, until)
+		if ctx.Err() != nil {
+			return
+		}
+
+		next := schedule.Interval
+		if err != nil {
+			next = backoff.Step()
+			if retryAfter, ok := proxy.RetryAfter(err); ok && retryAfter > next {
+				next = retryAfter
+			}
+		} else {
+			backoff = newBackoff(schedule.Interval)
+
+			// Search again the end of the previous window, in case some spans were reported late
+			since = p.Watermark()
+			if since.Before(start) {
+				since = start
+			}
+		}
+
+		if !emit(Result{Spans: spans, Err: err, Next: next}) {
+			return
+		}
+
+		timer := time.NewTimer(next)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+		until = time.Now().Add(-schedule.Delay)
+	}
+}
+
+// newBackoff returns the delays between polls after failures
+func newBackoff(interval time.Duration) wait.Backoff {
+	return wait.Backoff{
+		Duration: interval,
+		Factor:   2,
+		Jitter:   0.2,
+		Steps:    math.MaxInt32,
+		Cap:      maxBackoff,
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package poller incrementally fetches the spans of traces as they are reported
+package poller
+
+import (
+	"context"
+	"time"
+
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+// Late-arrival policies.
+//
+// Zipkin indexes spans asynchronously, once they are finished, so a span may
+// be returned after the watermark has passed its start time.
+const (
+	// LateShow returns late spans as soon as they are found
+	LateShow = "show"
+
+	// LateDrop ignores late spans
+	LateDrop = "drop"
+)
+
+// seenCapacity is the maximum number of spans remembered to avoid displaying them twice
+const seenCapacity = 100000
+
+// Poller fetches the spans matching a query, returning each span only once
+// across services and polls.
+//
+// Successive polls are expected to overlap so that spans reported late are
+// not missed.
+type Poller struct {
+	store    trace.Store
+	services []string
+	late     string
+	overlap  time.Duration
+
+	seen *trace.Seen
+
+	// watermark is the time before which all spans have been returned, provided
+	// they were reported within the overlap. The next poll is expected to
+	// start at the watermark.
+	watermark time.Time
+}
+
+// New creates a poller searching the given services, or all services when empty
+func New(store trace.Store, services []string, late string, overlap time.Duration) *Poller {
+	return &Poller{
+		store:    store,
+		services: services,
+		late:     late,
+		overlap:  overlap,
+		seen:     trace.NewSeen(seenCapacity),
+	}
+}
+
+// Poll returns the spans of the traces matching the query between since and until
+// that have not been returned yet, and advances the watermark.
+func (p *Poller) Poll(ctx context.Context, query trace.Query, since, until time.Time) ([]trace.Span, error) {
+	query.End = until
+	query.Lookback = until.Sub(since)
+
+	spans, err := p.fetch(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var fresh []trace.Span
+	for _, span := range spans {
+		if !p.seen.Add(span) {
+			continue
+		}
+
+		if p.late == LateDrop && p.isLate(span) {
+			continue
+		}
+
+		fresh = append(fresh, span)
+	}
+
+	if watermark := until.Add(-p.overlap); watermark.After(p.watermark) {
+		p.watermark = watermark
+	}
+	return fresh, nil
+}
+
+// Watermark returns the time before which all spans have been returned
+func (p *Poller) Watermark() time.Time {
+	return p.watermark
+}
+
+// isLate returns true when the span started before the watermark
+func (p *Poller) isLate(span trace.Span) bool {
+	return !p.watermark.IsZero() && span.Timestamp.Before(p.watermark)
+}
+
+// fetch returns the spans of all the traces matching the query
+func (p *Poller) fetch(ctx context.Context, query trace.Query) ([]trace.Span, error) {
+	services := p.services
+	if len(services) == 0 {
+		var err error
+		services, err = p.store.Services(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The same trace is returned once per service it goes through
+	var spans []trace.Span
+	for _, svc := range services {
+		query.ServiceName = svc
+		traces, err := p.store.Search(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range traces {
+			spans = append(spans, t...)
+		}
+	}
+	return spans, nil
+}
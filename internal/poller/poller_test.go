@@ -0,0 +1,105 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package poller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+	"knative.dev/kn-plugin-trace/pkg/trace/fake"
+)
+
+var now = time.Date(2021, 11, 4, 10, 0, 0, 0, time.UTC)
+
+func span(traceID, id, parentID, service string, timestamp time.Time) trace.Span {
+	return trace.Span{
+		TraceID:       traceID,
+		ID:            id,
+		ParentID:      parentID,
+		Timestamp:     timestamp,
+		Duration:      time.Millisecond,
+		LocalEndpoint: &trace.Endpoint{ServiceName: service},
+	}
+}
+
+func TestPollDeduplicates(t *testing.T) {
+	store := fake.NewStore(
+		span("1", "a", "", "broker-ingress", now.Add(-10*time.Second)),
+		span("1", "b", "a", "broker-filter", now.Add(-9*time.Second)),
+	)
+
+	p := New(store, nil, LateShow, 30*time.Second)
+
+	// The trace goes through both services but is returned once
+	spans, err := p.Poll(context.Background(), trace.Query{}, now.Add(-time.Minute), now)
+	assert.NilError(t, err)
+	assert.Equal(t, len(spans), 2)
+	assert.Equal(t, len(store.Queries), 2)
+	assert.Equal(t, p.watermark, now.Add(-30*time.Second))
+
+	// Overlapping polls only return new spans
+	store.Add(span("1", "c", "b", "broker-filter", now.Add(time.Second)))
+	spans, err = p.Poll(context.Background(), trace.Query{}, p.watermark, now.Add(5*time.Second))
+	assert.NilError(t, err)
+	assert.Equal(t, len(spans), 1)
+	assert.Equal(t, spans[0].ID, "c")
+}
+
+func TestPollDropsLateSpans(t *testing.T) {
+	store := fake.NewStore(span("1", "a", "", "broker-ingress", now.Add(-10*time.Second)))
+
+	p := New(store, []string{"broker-ingress"}, LateDrop, 5*time.Second)
+	_, err := p.Poll(context.Background(), trace.Query{}, now.Add(-time.Minute), now)
+	assert.NilError(t, err)
+
+	// Reported after the watermark passed its start time
+	store.Add(span("2", "b", "", "broker-ingress", now.Add(-8*time.Second)))
+	spans, err := p.Poll(context.Background(), trace.Query{}, p.watermark, now.Add(time.Second))
+	assert.NilError(t, err)
+	assert.Equal(t, len(spans), 0)
+}
+
+func TestFollow(t *testing.T) {
+	store := fake.NewStore(span("1", "a", "", "broker-ingress", now.Add(-10*time.Second)))
+	store.Err = errors.New("unavailable")
+
+	p := New(store, []string{"broker-ingress"}, LateShow, 0)
+	schedule := Schedule{Interval: time.Millisecond}
+
+	var results []Result
+	p.Follow(context.Background(), trace.Query{}, now.Add(-time.Minute), now, schedule, func(r Result) bool {
+		results = append(results, r)
+		store.Err = nil
+		return len(results) < 3
+	})
+
+	assert.Equal(t, len(results), 3)
+	assert.ErrorContains(t, results[0].Err, "unavailable")
+	assert.Assert(t, results[0].Next >= schedule.Interval)
+	assert.NilError(t, results[1].Err)
+	assert.Equal(t, len(results[1].Spans), 1)
+	assert.Equal(t, results[1].Next, schedule.Interval)
+
+	// Spans are only returned once
+	assert.Equal(t, len(results[2].Spans), 0)
+
+	// Polls search again from the watermark, never before the start
+	last := store.Queries[len(store.Queries)-1]
+	assert.Assert(t, !last.End.Add(-last.Lookback).Before(now.Add(-time.Minute)))
+}
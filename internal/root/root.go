@@ -15,8 +15,16 @@
 package root
 
 import (
+	"context"
+	"os"
+	"os/signal"
+
 	"github.com/spf13/cobra"
+	"knative.dev/kn-plugin-trace/internal/commands/browse"
 	"knative.dev/kn-plugin-trace/internal/commands/config"
+	"knative.dev/kn-plugin-trace/internal/commands/event"
+	"knative.dev/kn-plugin-trace/internal/commands/get"
+	"knative.dev/kn-plugin-trace/internal/commands/graph"
 	"knative.dev/kn-plugin-trace/internal/commands/show"
 
 	clientcmds "knative.dev/client/pkg/kn/commands"
@@ -35,10 +43,35 @@ func NewRootCommand() *cobra.Command {
 	p := &clientcmds.KnParams{}
 	p.Initialize()
 
+	// Same global flags as kn
+	rootCmd.PersistentFlags().StringVar(&p.KubeCfgPath, "kubeconfig", "", "kubectl configuration file (default: ~/.kube/config)")
+	rootCmd.PersistentFlags().StringVar(&p.KubeContext, "context", "", "name of the kubeconfig context to use")
+	rootCmd.PersistentFlags().StringVar(&p.KubeCluster, "cluster", "", "name of the kubeconfig cluster to use")
+	rootCmd.PersistentFlags().BoolVar(&p.LogHTTP, "log-http", false, "log http traffic")
+
 	rootCmd.AddCommand(config.NewConfigCommand(p))
 
 	rootCmd.AddCommand(show.NewShowCommand(p))
+	rootCmd.AddCommand(browse.NewBrowseCommand(p))
+	rootCmd.AddCommand(event.NewEventCommand(p))
+	rootCmd.AddCommand(get.NewGetCommand(p))
+	rootCmd.AddCommand(graph.NewGraphCommand(p))
 	rootCmd.AddCommand(commands.NewVersionCommand())
 
 	return rootCmd
 }
+
+// Execute runs the command until completion or until interrupted. The first
+// interrupt cancels the command context so that it can shut down cleanly,
+// the second one terminates the process.
+func Execute(cmd *cobra.Command) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	return cmd.ExecuteContext(ctx)
+}
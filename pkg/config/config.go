@@ -17,15 +17,84 @@ package config
 import (
 	"context"
 	"errors"
+	"fmt"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
 	"knative.dev/pkg/tracing/config"
 )
 
-func Load(ctx context.Context, client kubernetes.Interface) (*config.Config, error) {
-	cm, err := client.CoreV1().ConfigMaps("knative-eventing").Get(ctx, "config-tracing", metav1.GetOptions{})
+// Knative components with a tracing configuration
+const (
+	Eventing = "eventing"
+	Serving  = "serving"
+
+	// All selects both Eventing and Serving
+	All = "all"
+)
+
+// ConfigMapName is the name of the tracing ConfigMap of Knative components
+const ConfigMapName = "config-tracing"
+
+// Target is the tracing ConfigMap of a Knative component
+type Target struct {
+	Component string
+	Namespace string
+}
+
+func (t Target) String() string {
+	return t.Component + " (" + t.Namespace + "/" + ConfigMapName + ")"
+}
+
+// Targets returns the tracing ConfigMaps of the given component in the given
+// namespaces. All returns Eventing then Serving.
+func Targets(component string, namespaces Namespaces) ([]Target, error) {
+	eventing := Target{Component: Eventing, Namespace: namespaces.Eventing}
+	if eventing.Namespace == "" {
+		eventing.Namespace = DefaultEventingNamespace
+	}
+	serving := Target{Component: Serving, Namespace: namespaces.Serving}
+	if serving.Namespace == "" {
+		serving.Namespace = DefaultServingNamespace
+	}
+
+	switch component {
+	case Eventing:
+		return []Target{eventing}, nil
+	case Serving:
+		return []Target{serving}, nil
+	case All:
+		return []Target{eventing, serving}, nil
+	default:
+		return nil, fmt.Errorf("invalid component %q. Must be one of: %s, %s, %s", component, Eventing, Serving, All)
+	}
+}
+
+// Installed returns the targets whose namespace exists. It fails when none exists.
+func Installed(ctx context.Context, client kubernetes.Interface, targets []Target) ([]Target, error) {
+	var installed []Target
+	for _, target := range targets {
+		_, err := client.CoreV1().Namespaces().Get(ctx, target.Namespace, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		installed = append(installed, target)
+	}
+
+	if len(installed) == 0 {
+		return nil, errors.New("neither Knative Eventing nor Knative Serving is installed")
+	}
+	return installed, nil
+}
+
+// Load returns the tracing configuration of the given target
+func Load(ctx context.Context, client kubernetes.Interface, target Target) (*config.Config, error) {
+	cm, err := client.CoreV1().ConfigMaps(target.Namespace).Get(ctx, ConfigMapName, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -33,6 +102,36 @@ func Load(ctx context.Context, client kubernetes.Interface) (*config.Config, err
 	return config.NewTracingConfigFromConfigMap(cm)
 }
 
+// LoadZipkin returns the tracing configuration of the first target sending
+// spans to a Zipkin endpoint. Targets without configuration, or with another
+// backend, are skipped. When none qualifies, the reason the first configuration
+// is rejected is returned.
+func LoadZipkin(ctx context.Context, client kubernetes.Interface, targets []Target) (*config.Config, error) {
+	var rejected error
+	for _, target := range targets {
+		cfg, err := Load(ctx, client, target)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		err = Validate(cfg)
+		if err == nil {
+			return cfg, nil
+		}
+		if rejected == nil {
+			rejected = fmt.Errorf("%s: %w", target, err)
+		}
+	}
+
+	if rejected != nil {
+		return nil, rejected
+	}
+	return nil, errors.New("no tracing configuration to load")
+}
+
 // Validate the given configuration is compatible with kn trace
 func Validate(cfg *config.Config) error {
 	if cfg.Backend != "zipkin" {
@@ -48,3 +147,21 @@ func Validate(cfg *config.Config) error {
 
 	return nil
 }
+
+// Differences returns the settings which differ between the two configurations
+func Differences(a, b *config.Config) []string {
+	var diffs []string
+	if a.Backend != b.Backend {
+		diffs = append(diffs, "backend")
+	}
+	if a.ZipkinEndpoint != b.ZipkinEndpoint {
+		diffs = append(diffs, "zipkin-endpoint")
+	}
+	if a.Debug != b.Debug {
+		diffs = append(diffs, "debug")
+	}
+	if a.SampleRate != b.SampleRate {
+		diffs = append(diffs, "sample-rate")
+	}
+	return diffs
+}
@@ -0,0 +1,76 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"knative.dev/pkg/tracing/config"
+)
+
+func TestInstalled(t *testing.T) {
+	targets, err := Targets(All, Namespaces{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(targets), 2)
+
+	_, err = Targets("kafka", Namespaces{})
+	assert.ErrorContains(t, err, "invalid component")
+
+	client := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "knative-serving"}})
+	installed, err := Installed(context.Background(), client, targets)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, installed, []Target{{Component: Serving, Namespace: "knative-serving"}})
+
+	_, err = Installed(context.Background(), fake.NewSimpleClientset(), targets)
+	assert.ErrorContains(t, err, "neither")
+}
+
+func TestLoadZipkin(t *testing.T) {
+	ctx := context.Background()
+	targets, err := Targets(All, Namespaces{})
+	assert.NilError(t, err)
+
+	tracing := func(namespace string, data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName, Namespace: namespace}, Data: data}
+	}
+
+	// Only Serving sends spans to Zipkin
+	client := fake.NewSimpleClientset(
+		tracing("knative-eventing", map[string]string{"backend": "none"}),
+		tracing("knative-serving", map[string]string{"backend": "zipkin", "zipkin-endpoint": "http://zipkin.kntools:9411/api/v2/spans"}),
+	)
+	cfg, err := LoadZipkin(ctx, client, targets)
+	assert.NilError(t, err)
+	assert.Equal(t, cfg.ZipkinEndpoint, "http://zipkin.kntools:9411/api/v2/spans")
+
+	client = fake.NewSimpleClientset(tracing("knative-eventing", map[string]string{"backend": "none"}))
+	_, err = LoadZipkin(ctx, client, targets)
+	assert.ErrorContains(t, err, "eventing")
+
+	_, err = LoadZipkin(ctx, fake.NewSimpleClientset(), targets)
+	assert.ErrorContains(t, err, "no tracing configuration")
+}
+
+func TestDifferences(t *testing.T) {
+	a := &config.Config{Backend: config.Zipkin, ZipkinEndpoint: "http://zipkin", SampleRate: 0.1}
+	b := &config.Config{Backend: config.Zipkin, ZipkinEndpoint: "http://zipkin", SampleRate: 0.1, Debug: true}
+	assert.DeepEqual(t, Differences(a, b), []string{"debug"})
+	assert.Equal(t, len(Differences(a, a)), 0)
+}
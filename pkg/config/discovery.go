@@ -0,0 +1,158 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Namespaces where Knative components are installed by default
+const (
+	DefaultEventingNamespace = "knative-eventing"
+	DefaultServingNamespace  = "knative-serving"
+)
+
+// Namespaces are the installation namespaces of Knative components. Empty
+// namespaces are discovered.
+type Namespaces struct {
+	Eventing string
+	Serving  string
+}
+
+// operatorVersions are the versions of the Knative Operator API, most recent first
+var operatorVersions = []string{"v1beta1", "v1alpha1"}
+
+// discovery describes how to find the installation namespace of a component
+type discovery struct {
+	// name is the value of the app.kubernetes.io/name label of the namespace and controller
+	name string
+
+	// releaseLabel is set on the namespace by releases predating the well-known labels
+	releaseLabel string
+
+	// controller is the app.kubernetes.io/component label of the controller deployment
+	controller string
+
+	// resource is the plural name of the Knative Operator custom resource
+	resource string
+
+	fallback string
+}
+
+var (
+	eventingDiscovery = discovery{
+		name:         "knative-eventing",
+		releaseLabel: "eventing.knative.dev/release",
+		controller:   "eventing-controller",
+		resource:     "knativeeventings",
+		fallback:     DefaultEventingNamespace,
+	}
+	servingDiscovery = discovery{
+		name:         "knative-serving",
+		releaseLabel: "serving.knative.dev/release",
+		controller:   "controller",
+		resource:     "knativeservings",
+		fallback:     DefaultServingNamespace,
+	}
+)
+
+// Discover returns the installation namespaces of Eventing and Serving, keeping
+// the given overrides. Namespaces are looked up with the well-known labels, then
+// the controller deployments, then the Knative Operator custom resources and
+// default to knative-eventing and knative-serving.
+//
+// Discovery is best-effort: lookups which are forbidden or fail are skipped.
+// The dynamic client may be nil.
+func Discover(ctx context.Context, client kubernetes.Interface, dyn dynamic.Interface, overrides Namespaces) Namespaces {
+	namespaces := overrides
+	if namespaces.Eventing == "" {
+		namespaces.Eventing = eventingDiscovery.namespace(ctx, client, dyn)
+	}
+	if namespaces.Serving == "" {
+		namespaces.Serving = servingDiscovery.namespace(ctx, client, dyn)
+	}
+	return namespaces
+}
+
+func (d discovery) namespace(ctx context.Context, client kubernetes.Interface, dyn dynamic.Interface) string {
+	if client != nil {
+		for _, selector := range []string{"app.kubernetes.io/name=" + d.name, d.releaseLabel} {
+			list, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: selector})
+			if err != nil {
+				continue
+			}
+
+			var candidates []string
+			for _, ns := range list.Items {
+				candidates = append(candidates, ns.Name)
+			}
+			if ns := d.pick(candidates); ns != "" {
+				return ns
+			}
+		}
+
+		selector := "app.kubernetes.io/name=" + d.name + ",app.kubernetes.io/component=" + d.controller
+		if list, err := client.AppsV1().Deployments("").List(ctx, metav1.ListOptions{LabelSelector: selector}); err == nil {
+			var candidates []string
+			for _, deployment := range list.Items {
+				candidates = append(candidates, deployment.Namespace)
+			}
+			if ns := d.pick(candidates); ns != "" {
+				return ns
+			}
+		}
+	}
+
+	if dyn != nil {
+		for _, version := range operatorVersions {
+			gvr := schema.GroupVersionResource{Group: "operator.knative.dev", Version: version, Resource: d.resource}
+			list, err := dyn.Resource(gvr).Namespace("").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				continue
+			}
+
+			var candidates []string
+			for _, cr := range list.Items {
+				candidates = append(candidates, cr.GetNamespace())
+			}
+			if ns := d.pick(candidates); ns != "" {
+				return ns
+			}
+		}
+	}
+
+	return d.fallback
+}
+
+// pick returns the default namespace when it is a candidate, the first
+// candidate in alphabetical order otherwise
+func (d discovery) pick(candidates []string) string {
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.Strings(candidates)
+	for _, candidate := range candidates {
+		if candidate == d.fallback {
+			return candidate
+		}
+	}
+	return candidates[0]
+}
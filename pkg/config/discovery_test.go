@@ -0,0 +1,60 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDiscover(t *testing.T) {
+	ctx := context.Background()
+
+	client := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-eventing", Labels: map[string]string{"app.kubernetes.io/name": "knative-eventing"}}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "controller", Namespace: "serverless", Labels: map[string]string{
+			"app.kubernetes.io/name":      "knative-serving",
+			"app.kubernetes.io/component": "controller",
+		}}},
+	)
+	assert.Equal(t, Discover(ctx, client, nil, Namespaces{}), Namespaces{Eventing: "openshift-eventing", Serving: "serverless"})
+
+	// Overrides are kept
+	assert.Equal(t, Discover(ctx, client, nil, Namespaces{Eventing: "events"}), Namespaces{Eventing: "events", Serving: "serverless"})
+
+	// Knative Operator
+	cr := &unstructured.Unstructured{}
+	cr.SetAPIVersion("operator.knative.dev/v1beta1")
+	cr.SetKind("KnativeServing")
+	cr.SetNamespace("operated")
+	cr.SetName("knative-serving")
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Group: "operator.knative.dev", Version: "v1beta1", Resource: "knativeservings"}:   "KnativeServingList",
+		{Group: "operator.knative.dev", Version: "v1beta1", Resource: "knativeeventings"}:  "KnativeEventingList",
+		{Group: "operator.knative.dev", Version: "v1alpha1", Resource: "knativeservings"}:  "KnativeServingList",
+		{Group: "operator.knative.dev", Version: "v1alpha1", Resource: "knativeeventings"}: "KnativeEventingList",
+	}, cr)
+	assert.Equal(t, Discover(ctx, fake.NewSimpleClientset(), dyn, Namespaces{}), Namespaces{Eventing: DefaultEventingNamespace, Serving: "operated"})
+}
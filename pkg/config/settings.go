@@ -0,0 +1,74 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"knative.dev/pkg/tracing/config"
+)
+
+// Keys of the tracing ConfigMap
+const (
+	BackendKey        = "backend"
+	ZipkinEndpointKey = "zipkin-endpoint"
+	DebugKey          = "debug"
+	SampleRateKey     = "sample-rate"
+)
+
+// Change is a setting modified in a tracing ConfigMap. Before and After are
+// empty when the setting is not set.
+type Change struct {
+	Key    string
+	Before string
+	After  string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Key, valueOrUnset(c.Before), valueOrUnset(c.After))
+}
+
+func valueOrUnset(value string) string {
+	if value == "" {
+		return "<unset>"
+	}
+	return value
+}
+
+// Apply returns the ConfigMap data with the given settings, and the changes
+// sorted by key. The result is parsed the same way Knative components parse
+// it and is rejected when invalid. The given data is not modified.
+func Apply(data map[string]string, settings map[string]string) (map[string]string, []Change, error) {
+	result := make(map[string]string, len(data)+len(settings))
+	for key, value := range data {
+		result[key] = value
+	}
+
+	var changes []Change
+	for key, value := range settings {
+		if before, ok := data[key]; ok && before == value {
+			continue
+		}
+		changes = append(changes, Change{Key: key, Before: data[key], After: value})
+		result[key] = value
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+
+	if _, err := config.NewTracingConfigFromMap(result); err != nil {
+		return nil, nil, fmt.Errorf("invalid tracing configuration: %w", err)
+	}
+	return result, changes, nil
+}
@@ -0,0 +1,44 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestApply(t *testing.T) {
+	data := map[string]string{BackendKey: "zipkin", ZipkinEndpointKey: "http://zipkin:9411/api/v2/spans", DebugKey: "false"}
+
+	result, changes, err := Apply(data, map[string]string{DebugKey: "true", SampleRateKey: "0.5", BackendKey: "zipkin"})
+	assert.NilError(t, err)
+	assert.Equal(t, result[DebugKey], "true")
+	assert.Equal(t, data[DebugKey], "false")
+	assert.DeepEqual(t, changes, []Change{
+		{Key: DebugKey, Before: "false", After: "true"},
+		{Key: SampleRateKey, After: "0.5"},
+	})
+	assert.Equal(t, changes[1].String(), "sample-rate: <unset> -> 0.5")
+
+	_, _, err = Apply(data, map[string]string{SampleRateKey: "2"})
+	assert.ErrorContains(t, err, "[0, 1] range")
+
+	_, _, err = Apply(map[string]string{}, map[string]string{BackendKey: "zipkin"})
+	assert.ErrorContains(t, err, "without a zipkin endpoint")
+
+	_, _, err = Apply(data, map[string]string{BackendKey: "jaeger"})
+	assert.ErrorContains(t, err, "unsupported tracing backend")
+}
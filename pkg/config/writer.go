@@ -0,0 +1,123 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// FieldManager is the field manager of the changes kn trace makes to tracing ConfigMaps
+const FieldManager = "kn-trace"
+
+// PreviousAnnotation records the settings of a tracing ConfigMap before kn trace enabled tracing
+const PreviousAnnotation = "kn-trace.knative.dev/previous"
+
+// ownedKeys are the keys of a tracing ConfigMap written by kn trace
+var ownedKeys = []string{BackendKey, ZipkinEndpointKey, DebugKey, SampleRateKey}
+
+// ownedAnnotations are the annotations of a tracing ConfigMap written by kn trace
+var ownedAnnotations = []string{PreviousAnnotation}
+
+// Mutate changes the tracing ConfigMap, whose data and annotations are never
+// nil. It returns false when nothing changed.
+type Mutate func(cm *corev1.ConfigMap) (bool, error)
+
+// Write reads the tracing ConfigMap of the target, calls mutate and writes
+// the keys owned by kn trace with a single server-side apply, creating the
+// ConfigMap when absent. Other keys are left untouched. Keys deleted by mutate
+// are removed only when kn trace wrote them. On conflicts, the ConfigMap is
+// read again and mutate is called again. It returns false when mutate changed
+// nothing.
+func Write(ctx context.Context, client kubernetes.Interface, target Target, mutate Mutate) (bool, error) {
+	changed := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current, err := client.CoreV1().ConfigMaps(target.Namespace).Get(ctx, ConfigMapName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			current = nil
+		} else if err != nil {
+			return err
+		}
+
+		desired := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName, Namespace: target.Namespace}}
+		if current != nil {
+			desired = current.DeepCopy()
+		}
+		if desired.Data == nil {
+			desired.Data = map[string]string{}
+		}
+		if desired.Annotations == nil {
+			desired.Annotations = map[string]string{}
+		}
+
+		changed, err = mutate(desired)
+		if err != nil || !changed {
+			return err
+		}
+
+		resourceVersion := ""
+		if current != nil {
+			resourceVersion = current.ResourceVersion
+		}
+		return apply(ctx, client, desired, resourceVersion)
+	})
+	return changed, err
+}
+
+// apply sets the owned keys and annotations of the desired ConfigMap. Keys
+// and annotations previously applied by kn trace and no longer desired are
+// removed by the API server, in the same request. The resource version, when
+// set, guards against concurrent changes.
+func apply(ctx context.Context, client kubernetes.Interface, desired *corev1.ConfigMap, resourceVersion string) error {
+	cm := corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            desired.Name,
+			Namespace:       desired.Namespace,
+			ResourceVersion: resourceVersion,
+		},
+		Data: map[string]string{},
+	}
+	for _, key := range ownedKeys {
+		if value, ok := desired.Data[key]; ok {
+			cm.Data[key] = value
+		}
+	}
+	for _, key := range ownedAnnotations {
+		if value, ok := desired.Annotations[key]; ok {
+			if cm.Annotations == nil {
+				cm.Annotations = map[string]string{}
+			}
+			cm.Annotations[key] = value
+		}
+	}
+
+	body, err := json.Marshal(cm)
+	if err != nil {
+		return err
+	}
+
+	// Keys set by Knative installers are owned by other managers
+	force := true
+	_, err = client.CoreV1().ConfigMaps(desired.Namespace).Patch(ctx, desired.Name, types.ApplyPatchType, body, metav1.PatchOptions{FieldManager: FieldManager, Force: &force})
+	return err
+}
@@ -0,0 +1,96 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8stesting "k8s.io/client-go/testing"
+	"knative.dev/kn-plugin-trace/internal/config/fake"
+)
+
+func TestWrite(t *testing.T) {
+	ctx := context.Background()
+	target := Target{Component: Eventing, Namespace: "knative-eventing"}
+	enable := func(cm *corev1.ConfigMap) (bool, error) {
+		cm.Data[BackendKey] = "zipkin"
+		cm.Data[ZipkinEndpointKey] = "http://zipkin:9411/api/v2/spans"
+		return true, nil
+	}
+
+	// Created when absent
+	client := fake.NewClientset()
+	changed, err := Write(ctx, client, target, enable)
+	assert.NilError(t, err)
+	assert.Assert(t, changed)
+	cm, err := client.CoreV1().ConfigMaps(target.Namespace).Get(ctx, ConfigMapName, metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, cm.Data[BackendKey], "zipkin")
+
+	// Only owned keys are written, and retried on conflicts
+	client = fake.NewClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName, Namespace: target.Namespace},
+		Data:       map[string]string{"_example": "docs", SampleRateKey: "0.1"},
+	})
+	conflicts := 1
+	var patches []types.PatchType
+	client.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		patches = append(patches, patch.GetPatchType())
+		if conflicts > 0 {
+			conflicts--
+			return true, nil, apierrors.NewConflict(action.GetResource().GroupResource(), ConfigMapName, nil)
+		}
+		return false, nil, nil
+	})
+
+	changed, err = Write(ctx, client, target, enable)
+	assert.NilError(t, err)
+	assert.Assert(t, changed)
+	assert.DeepEqual(t, patches, []types.PatchType{types.ApplyPatchType, types.ApplyPatchType})
+
+	cm, err = client.CoreV1().ConfigMaps(target.Namespace).Get(ctx, ConfigMapName, metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, cm.Data, map[string]string{"_example": "docs", SampleRateKey: "0.1", BackendKey: "zipkin", ZipkinEndpointKey: "http://zipkin:9411/api/v2/spans"})
+
+	// Deleted keys are removed by the same apply
+	patches = nil
+	changed, err = Write(ctx, client, target, func(cm *corev1.ConfigMap) (bool, error) {
+		cm.Data[BackendKey] = "none"
+		delete(cm.Data, ZipkinEndpointKey)
+		return true, nil
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, changed)
+	assert.DeepEqual(t, patches, []types.PatchType{types.ApplyPatchType})
+
+	cm, err = client.CoreV1().ConfigMaps(target.Namespace).Get(ctx, ConfigMapName, metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, cm.Data, map[string]string{"_example": "docs", SampleRateKey: "0.1", BackendKey: "none"})
+
+	// Nothing is written when unchanged
+	patches = nil
+	changed, err = Write(ctx, client, target, func(cm *corev1.ConfigMap) (bool, error) { return false, nil })
+	assert.NilError(t, err)
+	assert.Assert(t, !changed)
+	assert.Equal(t, len(patches), 0)
+}
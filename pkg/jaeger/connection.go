@@ -0,0 +1,219 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"knative.dev/kn-plugin-trace/pkg/proxy"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+// QueryPort is the port of the Jaeger query service
+const QueryPort = 16686
+
+var _ trace.Store = (*Connection)(nil)
+
+type Connection struct {
+	client proxy.Client
+}
+
+// Connect connects to the Jaeger query service next to the collector receiving
+// spans on the given Zipkin endpoint.
+//
+// Following the Jaeger operator conventions, the query service of the
+// "<name>-collector" service is "<name>-query".
+func Connect(ctx context.Context, endpoint string, restcfg *rest.Config, options proxy.Options) (*Connection, error) {
+	url, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	parts := regexp.MustCompile("[.:]").Split(url.Host, -1)
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("malformed endpoint %q", endpoint)
+	}
+
+	svcName := fmt.Sprintf("%s:%d", strings.TrimSuffix(parts[0], "-collector")+"-query", QueryPort)
+	client, err := proxy.NewClient(ctx, restcfg, svcName, parts[1], options.Transport)
+	if err != nil {
+		return nil, err
+	}
+
+	connection := Connection{client: client}
+
+	// Check if endpoint is reachable
+	_, err = connection.Services(ctx)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return &connection, nil
+}
+
+// Close releases the connections to the Jaeger query service
+func (c *Connection) Close() error {
+	return c.client.Close()
+}
+
+// Services returns the names of the services which reported spans
+func (c *Connection) Services(ctx context.Context) ([]string, error) {
+	var services ServicesResponse
+	if err := c.get(ctx, "api/services", &services); err != nil {
+		return nil, err
+	}
+
+	if len(services.Errors) > 0 {
+		return nil, responseError(services.Errors)
+	}
+
+	return services.Data, nil
+}
+
+// Search returns the traces matching the query. Jaeger only searches traces
+// by service so all services are searched when the query has none.
+func (c *Connection) Search(ctx context.Context, query trace.Query) ([][]trace.Span, error) {
+	services := []string{query.ServiceName}
+	if query.ServiceName == "" {
+		var err error
+		services, err = c.Services(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	start, end := query.Window(time.Now())
+
+	values := url.Values{}
+	values.Set("start", strconv.FormatInt(start.UnixMicro(), 10))
+	values.Set("end", strconv.FormatInt(end.UnixMicro(), 10))
+	if len(query.Tags) > 0 {
+		tags, err := json.Marshal(query.Tags)
+		if err != nil {
+			return nil, err
+		}
+		values.Set("tags", string(tags))
+	}
+	if query.MinDuration > 0 {
+		values.Set("minDuration", query.MinDuration.String())
+	}
+	if query.Limit > 0 {
+		values.Set("limit", strconv.Itoa(query.Limit))
+	}
+
+	var traces [][]trace.Span
+	seen := make(map[string]bool)
+	for _, service := range services {
+		values.Set("service", service)
+
+		var resp TracesResponse
+		if err := c.get(ctx, "api/traces?"+values.Encode(), &resp); err != nil {
+			return nil, err
+		}
+
+		if len(resp.Errors) > 0 {
+			return nil, responseError(resp.Errors)
+		}
+
+		for _, t := range resp.Data {
+			if seen[t.TraceID] {
+				continue
+			}
+			seen[t.TraceID] = true
+
+			spans, err := toSpans(t)
+			if err != nil {
+				return nil, err
+			}
+			traces = append(traces, spans)
+		}
+	}
+
+	return traces, nil
+}
+
+// Trace returns all the spans of the trace with the given ID
+func (c *Connection) Trace(ctx context.Context, traceID string) ([]trace.Span, error) {
+	var resp TracesResponse
+	if err := c.get(ctx, "api/traces/"+url.PathEscape(traceID), &resp); err != nil {
+		return nil, err
+	}
+
+	if len(resp.Errors) > 0 {
+		return nil, responseError(resp.Errors)
+	}
+
+	var spans []trace.Span
+	for _, t := range resp.Data {
+		s, err := toSpans(t)
+		if err != nil {
+			return nil, err
+		}
+		spans = append(spans, s...)
+	}
+	return spans, nil
+}
+
+// Dependencies returns the calls between services during the lookback period before end
+func (c *Connection) Dependencies(ctx context.Context, end time.Time, lookback time.Duration) ([]trace.Dependency, error) {
+	values := url.Values{}
+	values.Set("endTs", strconv.FormatInt(end.UnixMilli(), 10))
+	values.Set("lookback", strconv.FormatInt(lookback.Milliseconds(), 10))
+
+	var resp DependenciesResponse
+	if err := c.get(ctx, "api/dependencies?"+values.Encode(), &resp); err != nil {
+		return nil, err
+	}
+
+	if len(resp.Errors) > 0 {
+		return nil, responseError(resp.Errors)
+	}
+
+	deps := make([]trace.Dependency, 0, len(resp.Data))
+	for _, link := range resp.Data {
+		deps = append(deps, trace.Dependency{Parent: link.Parent, Child: link.Child, CallCount: link.CallCount})
+	}
+	return deps, nil
+}
+
+func (c *Connection) get(ctx context.Context, path string, v interface{}) error {
+	body, err := c.client.Get(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	// Preserve numbers in tags
+	decoder := json.NewDecoder(body)
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+func responseError(errs []ResponseError) error {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Msg)
+	}
+	return errors.New(strings.Join(msgs, ", "))
+}
@@ -0,0 +1,77 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"knative.dev/kn-plugin-trace/pkg/proxy"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+func TestSearch(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+		switch r.URL.Path {
+		case "/api/services":
+			w.Write([]byte(`{"data": ["broker-ingress", "event-display"]}`))
+		case "/api/traces":
+			// The same trace goes through both services
+			w.Write([]byte(`{"data": [` + traceJSON + `]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := proxy.NewHTTPClient(server.URL, proxy.HTTPOptions{})
+	assert.NilError(t, err)
+	connection := &Connection{client: client}
+
+	end := time.UnixMilli(1636020000000)
+	traces, err := connection.Search(context.Background(), trace.Query{
+		Tags:     map[string]string{"cloudevents.type": "dev.knative.ping"},
+		End:      end,
+		Lookback: time.Minute,
+		Limit:    10,
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(traces), 1)
+	assert.DeepEqual(t, requests, []string{
+		"/api/services",
+		"/api/traces?end=1636020000000000&limit=10&service=broker-ingress&start=1636019940000000&tags=%7B%22cloudevents.type%22%3A%22dev.knative.ping%22%7D",
+		"/api/traces?end=1636020000000000&limit=10&service=event-display&start=1636019940000000&tags=%7B%22cloudevents.type%22%3A%22dev.knative.ping%22%7D",
+	})
+}
+
+func TestSearchErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": null, "errors": [{"code": 400, "msg": "malformed tags"}, {"msg": "too many traces"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := proxy.NewHTTPClient(server.URL, proxy.HTTPOptions{})
+	assert.NilError(t, err)
+	connection := &Connection{client: client}
+
+	_, err = connection.Search(context.Background(), trace.Query{ServiceName: "broker-ingress"})
+	assert.Error(t, err, "malformed tags, too many traces")
+}
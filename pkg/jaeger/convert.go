@@ -0,0 +1,167 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+// Tags Jaeger uses to store Zipkin span fields
+const (
+	spanKindTag    = "span.kind"
+	peerServiceTag = "peer.service"
+)
+
+// toSpans converts a Jaeger trace to backend-agnostic spans
+func toSpans(t Trace) ([]trace.Span, error) {
+	spans := make([]trace.Span, 0, len(t.Spans))
+	for _, s := range t.Spans {
+		span, err := toSpan(s, t.Processes[s.ProcessID])
+		if err != nil {
+			return nil, err
+		}
+		spans = append(spans, span)
+	}
+	return spans, nil
+}
+
+func toSpan(s Span, process Process) (trace.Span, error) {
+	traceID, err := formatTraceID(s.TraceID)
+	if err != nil {
+		return trace.Span{}, err
+	}
+
+	id, err := formatID(s.SpanID)
+	if err != nil {
+		return trace.Span{}, err
+	}
+
+	span := trace.Span{
+		TraceID:   traceID,
+		ID:        id,
+		Name:      s.OperationName,
+		Timestamp: time.UnixMicro(s.StartTime),
+		Duration:  time.Duration(s.Duration) * time.Microsecond,
+		Tags:      make(map[string]string, len(s.Tags)),
+	}
+
+	// Spans have at most one parent in Zipkin
+	if ref, ok := parentReference(s); ok {
+		parentID, err := formatID(ref.SpanID)
+		if err != nil {
+			return trace.Span{}, err
+		}
+		span.ParentID = parentID
+	}
+
+	if process.ServiceName != "" {
+		span.LocalEndpoint = &trace.Endpoint{ServiceName: process.ServiceName}
+	}
+
+	for _, tag := range s.Tags {
+		value := fmt.Sprint(tag.Value)
+		switch tag.Key {
+		case spanKindTag:
+			span.Kind = trace.Kind(strings.ToUpper(value))
+		case peerServiceTag:
+			span.RemoteEndpoint = &trace.Endpoint{ServiceName: value}
+			span.Tags[tag.Key] = value
+		default:
+			span.Tags[tag.Key] = value
+		}
+	}
+
+	for _, log := range s.Logs {
+		span.Annotations = append(span.Annotations, trace.Annotation{
+			Timestamp: time.UnixMicro(log.Timestamp),
+			Value:     logValue(log),
+		})
+	}
+
+	return span, nil
+}
+
+// parentReference returns the reference to the parent of the span in the same
+// trace, preferring CHILD_OF over FOLLOWS_FROM references
+func parentReference(s Span) (Reference, bool) {
+	var follows *Reference
+	for i, ref := range s.References {
+		if ref.TraceID != "" && ref.TraceID != s.TraceID {
+			continue
+		}
+		switch ref.RefType {
+		case "CHILD_OF":
+			return ref, true
+		case "FOLLOWS_FROM":
+			if follows == nil {
+				follows = &s.References[i]
+			}
+		}
+	}
+	if follows != nil {
+		return *follows, true
+	}
+	return Reference{}, false
+}
+
+// logValue returns the event of a log, or all its fields when there is no event
+func logValue(log Log) string {
+	fields := make([]string, 0, len(log.Fields))
+	for _, field := range log.Fields {
+		if field.Key == "event" {
+			return fmt.Sprint(field.Value)
+		}
+		fields = append(fields, fmt.Sprintf("%s=%v", field.Key, field.Value))
+	}
+	return strings.Join(fields, " ")
+}
+
+// formatTraceID pads Jaeger trace IDs, which have no leading zeros, to 16 or 32 hex characters
+func formatTraceID(id string) (string, error) {
+	if len(id) > 16 {
+		high, err := strconv.ParseUint(id[:len(id)-16], 16, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid trace ID %q: %w", id, err)
+		}
+		low, err := strconv.ParseUint(id[len(id)-16:], 16, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid trace ID %q: %w", id, err)
+		}
+		if high != 0 {
+			return fmt.Sprintf("%016x%016x", high, low), nil
+		}
+		return fmt.Sprintf("%016x", low), nil
+	}
+
+	low, err := strconv.ParseUint(id, 16, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid trace ID %q: %w", id, err)
+	}
+	return fmt.Sprintf("%016x", low), nil
+}
+
+// formatID pads Jaeger span IDs to 16 hex characters
+func formatID(id string) (string, error) {
+	v, err := strconv.ParseUint(id, 16, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid span ID %q: %w", id, err)
+	}
+	return fmt.Sprintf("%016x", v), nil
+}
@@ -0,0 +1,119 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+const traceJSON = `{
+  "traceID": "1a2b",
+  "spans": [{
+    "traceID": "1a2b",
+    "spanID": "3",
+    "operationName": "broker:default.default",
+    "references": [
+      {"refType": "FOLLOWS_FROM", "traceID": "1a2b", "spanID": "1"},
+      {"refType": "CHILD_OF", "traceID": "ffff", "spanID": "5"},
+      {"refType": "CHILD_OF", "traceID": "1a2b", "spanID": "2"}
+    ],
+    "startTime": 1636020000000000,
+    "duration": 5000,
+    "tags": [
+      {"key": "span.kind", "type": "string", "value": "server"},
+      {"key": "peer.service", "type": "string", "value": "event-display"},
+      {"key": "http.status_code", "type": "int64", "value": 202}
+    ],
+    "logs": [
+      {"timestamp": 1636020000001000, "fields": [{"key": "event", "type": "string", "value": "dispatched"}]},
+      {"timestamp": 1636020000002000, "fields": [{"key": "retry", "type": "int64", "value": 1}]}
+    ],
+    "processID": "p1"
+  }],
+  "processes": {"p1": {"serviceName": "mt-broker-ingress"}}
+}`
+
+func decode(t *testing.T, data string, v interface{}) {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	assert.NilError(t, decoder.Decode(v))
+}
+
+func TestToSpans(t *testing.T) {
+	var jt Trace
+	decode(t, traceJSON, &jt)
+
+	spans, err := toSpans(jt)
+	assert.NilError(t, err)
+	assert.Equal(t, len(spans), 1)
+
+	span := spans[0]
+	assert.Equal(t, span.TraceID, "0000000000001a2b")
+	assert.Equal(t, span.ID, "0000000000000003")
+	assert.Equal(t, span.ParentID, "0000000000000002")
+	assert.Equal(t, span.Kind, trace.KindServer)
+	assert.Equal(t, span.LocalEndpoint.ServiceName, "mt-broker-ingress")
+	assert.Equal(t, span.RemoteEndpoint.ServiceName, "event-display")
+	assert.Equal(t, span.Timestamp, time.UnixMicro(1636020000000000))
+	assert.Equal(t, span.Duration, 5*time.Millisecond)
+	assert.DeepEqual(t, span.Tags, map[string]string{
+		"peer.service":     "event-display",
+		"http.status_code": "202",
+	})
+	assert.DeepEqual(t, span.Annotations, []trace.Annotation{
+		{Timestamp: time.UnixMicro(1636020000001000), Value: "dispatched"},
+		{Timestamp: time.UnixMicro(1636020000002000), Value: "retry=1"},
+	})
+}
+
+func TestParentReference(t *testing.T) {
+	follows := Span{TraceID: "1", References: []Reference{{RefType: "FOLLOWS_FROM", TraceID: "1", SpanID: "a"}}}
+	ref, ok := parentReference(follows)
+	assert.Assert(t, ok)
+	assert.Equal(t, ref.SpanID, "a")
+
+	// Links to other traces are not parents
+	linked := Span{TraceID: "1", References: []Reference{{RefType: "CHILD_OF", TraceID: "2", SpanID: "a"}}}
+	_, ok = parentReference(linked)
+	assert.Assert(t, !ok)
+}
+
+func TestFormatTraceID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{id: "1a2b", want: "0000000000001a2b"},
+		{id: "463ac35c9f6413ad", want: "463ac35c9f6413ad"},
+		{id: "1463ac35c9f6413ad", want: "0000000000000001463ac35c9f6413ad"},
+		{id: "48485a3953bb61246b221d5bc9e6496c", want: "48485a3953bb61246b221d5bc9e6496c"},
+		// 128-bit IDs with a zero high part are 64-bit IDs
+		{id: "00000000000000000000000000001a2b", want: "0000000000001a2b"},
+	}
+	for _, tt := range tests {
+		got, err := formatTraceID(tt.id)
+		assert.NilError(t, err)
+		assert.Equal(t, got, tt.want, tt.id)
+	}
+
+	_, err := formatTraceID("xyz")
+	assert.ErrorContains(t, err, "invalid trace ID")
+}
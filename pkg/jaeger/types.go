@@ -0,0 +1,84 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaeger
+
+// Types of the Jaeger query HTTP API responses
+
+type ServicesResponse struct {
+	Data   []string        `json:"data"`
+	Errors []ResponseError `json:"errors,omitempty"`
+}
+
+type TracesResponse struct {
+	Data   []Trace         `json:"data"`
+	Errors []ResponseError `json:"errors,omitempty"`
+}
+
+type DependenciesResponse struct {
+	Data   []DependencyLink `json:"data"`
+	Errors []ResponseError  `json:"errors,omitempty"`
+}
+
+type DependencyLink struct {
+	Parent    string `json:"parent"`
+	Child     string `json:"child"`
+	CallCount int64  `json:"callCount"`
+}
+
+type ResponseError struct {
+	Code    int    `json:"code,omitempty"`
+	Msg     string `json:"msg"`
+	TraceID string `json:"traceID,omitempty"`
+}
+
+type Trace struct {
+	TraceID   string             `json:"traceID"`
+	Spans     []Span             `json:"spans"`
+	Processes map[string]Process `json:"processes"`
+}
+
+type Span struct {
+	TraceID       string      `json:"traceID"`
+	SpanID        string      `json:"spanID"`
+	OperationName string      `json:"operationName"`
+	References    []Reference `json:"references"`
+	StartTime     int64       `json:"startTime"` // microseconds since epoch
+	Duration      int64       `json:"duration"`  // microseconds
+	Tags          []KeyValue  `json:"tags"`
+	Logs          []Log       `json:"logs"`
+	ProcessID     string      `json:"processID"`
+}
+
+type Reference struct {
+	RefType string `json:"refType"`
+	TraceID string `json:"traceID"`
+	SpanID  string `json:"spanID"`
+}
+
+type Process struct {
+	ServiceName string     `json:"serviceName"`
+	Tags        []KeyValue `json:"tags"`
+}
+
+type KeyValue struct {
+	Key   string      `json:"key"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+type Log struct {
+	Timestamp int64      `json:"timestamp"` // microseconds since epoch
+	Fields    []KeyValue `json:"fields"`
+}
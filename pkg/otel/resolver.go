@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net/url"
 	"regexp"
+	"strings"
 
 	"gopkg.in/yaml.v2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -29,38 +30,12 @@ import (
 
 // Resolve resolves the given endpoint to a real zipkin endpoint
 func ResolveZipkin(ctx context.Context, endpoint string, restcfg *rest.Config) (string, error) {
-	url, err := url.Parse(endpoint)
-	if err != nil {
-		return "", err
-	}
-
-	parts := regexp.MustCompile("[.:]").Split(url.Host, -1)
-	if len(parts) < 2 {
-		return "", fmt.Errorf("malformed endpoint %q", endpoint)
-	}
-
-	// let's assume otel is installed in the cluster
-	svcName := parts[0]
-	svcNamespace := parts[1]
-
 	kubeclient, err := kubernetes.NewForConfig(restcfg)
 	if err != nil {
 		return "", err
 	}
 
-	cm, err := kubeclient.CoreV1().ConfigMaps(svcNamespace).Get(ctx, svcName, metav1.GetOptions{})
-	if err != nil {
-		// wrong assumption. bail out
-		return "", err
-	}
-
-	collectorYAML, ok := cm.Data["collector.yaml"]
-	if !ok {
-		return "", errors.New("missing collector.yaml key")
-	}
-
-	var collectorCfg CollectorConfig
-	err = yaml.Unmarshal([]byte(collectorYAML), &collectorCfg)
+	collectorCfg, err := loadCollectorConfig(ctx, kubeclient, endpoint)
 	if err != nil {
 		return "", err
 	}
@@ -88,3 +63,126 @@ func ResolveZipkin(ctx context.Context, endpoint string, restcfg *rest.Config) (
 
 	return resolved.(string), nil
 }
+
+// ResolveTempo resolves the given endpoint to the endpoint of the Tempo
+// instance the OpenTelemetry collector exports traces to. When exporter is
+// not empty, it names the collector exporter sending traces to Tempo.
+// Otherwise the first otlp or otlphttp exporter pointing at a Tempo service
+// is used.
+func ResolveTempo(ctx context.Context, endpoint string, restcfg *rest.Config, exporter string) (string, error) {
+	kubeclient, err := kubernetes.NewForConfig(restcfg)
+	if err != nil {
+		return "", err
+	}
+
+	collectorCfg, err := loadCollectorConfig(ctx, kubeclient, endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	if !HasType(collectorCfg.Receivers, "zipkin") {
+		return "", errors.New("OpenTelemetry collector not receiving Zipkin traces")
+	}
+
+	return FindTempoExporter(collectorCfg, exporter, func(endpoint string) bool {
+		return IsTempoService(ctx, kubeclient, endpoint)
+	})
+}
+
+// FindTempoExporter returns the endpoint of the named exporter or, when name
+// is empty, of the first otlp or otlphttp exporter of the traces pipeline
+// for which isTempo returns true
+func FindTempoExporter(config CollectorConfig, name string, isTempo func(endpoint string) bool) (string, error) {
+	if name != "" {
+		settings, ok := config.Exporters[name]
+		if !ok {
+			return "", fmt.Errorf("OpenTelemetry collector has no exporter %q", name)
+		}
+		if !isOTLP(name) {
+			return "", fmt.Errorf("OpenTelemetry collector exporter %q is not an otlp or otlphttp exporter", name)
+		}
+		endpoint, ok := settings["endpoint"].(string)
+		if !ok {
+			return "", fmt.Errorf("OpenTelemetry collector exporter %q has no endpoint", name)
+		}
+		return endpoint, nil
+	}
+
+	for _, exporter := range config.Service.Pipelines.Traces.Exporters {
+		if !isOTLP(exporter) {
+			continue
+		}
+
+		endpoint, ok := config.Exporters[exporter]["endpoint"].(string)
+		if ok && isTempo(endpoint) {
+			return endpoint, nil
+		}
+	}
+	return "", errors.New("OpenTelemetry collector does not export traces to Tempo")
+}
+
+// isOTLP returns true when the exporter sends traces using the OpenTelemetry protocol
+func isOTLP(exporter string) bool {
+	typ := ParseFullName(exporter).Type
+	return typ == "otlp" || typ == "otlphttp"
+}
+
+// IsTempoService returns true when the given endpoint is served by a Kubernetes
+// service of Tempo, as labeled by the Tempo Helm charts and operator
+func IsTempoService(ctx context.Context, client kubernetes.Interface, endpoint string) bool {
+	// OTLP gRPC endpoints usually have no scheme
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+
+	url, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+	parts := regexp.MustCompile("[.:]").Split(url.Host, -1)
+	if len(parts) < 2 {
+		return false
+	}
+
+	svc, err := client.CoreV1().Services(parts[1]).Get(ctx, parts[0], metav1.GetOptions{})
+	if err != nil {
+		return false
+	}
+	return svc.Labels["app.kubernetes.io/name"] == "tempo"
+}
+
+// loadCollectorConfig reads the configuration of the OpenTelemetry collector
+// listening on the given endpoint
+func loadCollectorConfig(ctx context.Context, kubeclient kubernetes.Interface, endpoint string) (CollectorConfig, error) {
+	url, err := url.Parse(endpoint)
+	if err != nil {
+		return CollectorConfig{}, err
+	}
+
+	parts := regexp.MustCompile("[.:]").Split(url.Host, -1)
+	if len(parts) < 2 {
+		return CollectorConfig{}, fmt.Errorf("malformed endpoint %q", endpoint)
+	}
+
+	// let's assume otel is installed in the cluster
+	svcName := parts[0]
+	svcNamespace := parts[1]
+
+	cm, err := kubeclient.CoreV1().ConfigMaps(svcNamespace).Get(ctx, svcName, metav1.GetOptions{})
+	if err != nil {
+		// wrong assumption. bail out
+		return CollectorConfig{}, err
+	}
+
+	collectorYAML, ok := cm.Data["collector.yaml"]
+	if !ok {
+		return CollectorConfig{}, errors.New("missing collector.yaml key")
+	}
+
+	var collectorCfg CollectorConfig
+	err = yaml.Unmarshal([]byte(collectorYAML), &collectorCfg)
+	if err != nil {
+		return CollectorConfig{}, err
+	}
+	return collectorCfg, nil
+}
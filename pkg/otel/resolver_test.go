@@ -0,0 +1,135 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestFindTempoExporter(t *testing.T) {
+	tempoServices := map[string]bool{"tempo-distributor.observability:4317": true}
+	isTempo := func(endpoint string) bool { return tempoServices[endpoint] }
+
+	tests := []struct {
+		name      string
+		collector string
+		exporter  string
+		want      string
+		wantErr   string
+	}{{
+		name: "tempo exporter",
+		collector: `
+exporters:
+  logging: {}
+  otlp/traces:
+    endpoint: tempo-distributor.observability:4317
+service:
+  pipelines:
+    traces:
+      exporters: [logging, otlp/traces]
+`,
+		want: "tempo-distributor.observability:4317",
+	}, {
+		name: "non-tempo otlp exporter",
+		collector: `
+exporters:
+  otlp/tempo:
+    endpoint: jaeger-collector.observability:4317
+service:
+  pipelines:
+    traces:
+      exporters: [otlp/tempo]
+`,
+		wantErr: "does not export traces to Tempo",
+	}, {
+		name: "no exporter",
+		collector: `
+exporters: {}
+service:
+  pipelines:
+    traces:
+      exporters: []
+`,
+		wantErr: "does not export traces to Tempo",
+	}, {
+		name: "explicit exporter",
+		collector: `
+exporters:
+  otlphttp/backend:
+    endpoint: http://traces.observability:4318
+service:
+  pipelines:
+    traces:
+      exporters: [otlphttp/backend]
+`,
+		exporter: "otlphttp/backend",
+		want:     "http://traces.observability:4318",
+	}, {
+		name: "explicit exporter of another type",
+		collector: `
+exporters:
+  zipkin:
+    endpoint: http://zipkin.observability:9411/api/v2/spans
+service:
+  pipelines:
+    traces:
+      exporters: [zipkin]
+`,
+		exporter: "zipkin",
+		wantErr:  "not an otlp or otlphttp exporter",
+	}, {
+		name: "missing explicit exporter",
+		collector: `
+exporters: {}
+`,
+		exporter: "otlp/tempo",
+		wantErr:  "no exporter",
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var config CollectorConfig
+			assert.NilError(t, yaml.Unmarshal([]byte(tc.collector), &config))
+
+			got, err := FindTempoExporter(config, tc.exporter, isTempo)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, got, tc.want)
+		})
+	}
+}
+
+func TestIsTempoService(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "tempo-distributor", Namespace: "observability", Labels: map[string]string{"app.kubernetes.io/name": "tempo"}}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "jaeger-collector", Namespace: "observability"}},
+	)
+
+	assert.Assert(t, IsTempoService(ctx, client, "tempo-distributor.observability:4317"))
+	assert.Assert(t, IsTempoService(ctx, client, "http://tempo-distributor.observability.svc.cluster.local:4318"))
+	assert.Assert(t, !IsTempoService(ctx, client, "jaeger-collector.observability:4317"))
+	assert.Assert(t, !IsTempoService(ctx, client, "tempo.monitoring:4317"))
+}
@@ -41,7 +41,7 @@ func (pl *plugin) Execute(args []string) error {
 		os.Args = oldArgs
 	})()
 	os.Args = append([]string{"kn-trace"}, args...)
-	return cmd.Execute()
+	return root.Execute(cmd)
 }
 
 // Description is displayed in kn's help message
@@ -0,0 +1,105 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxErrorBody is the maximum number of bytes of an error response kept in a StatusError
+const maxErrorBody = 4096
+
+// StatusError is returned when the API server or the backend respond with an unexpected status
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+
+	// Body is the beginning of the response body
+	Body string
+}
+
+func newStatusError(resp *http.Response) *StatusError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Header:     resp.Header,
+		Body:       strings.TrimSpace(string(body)),
+	}
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return e.Status
+	}
+	return fmt.Sprintf("%s: %s", e.Status, e.Body)
+}
+
+// StatusCode returns the status code of the response the error is about, or 0
+func StatusCode(err error) int {
+	var serr *StatusError
+	if errors.As(err, &serr) {
+		return serr.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound returns true when the error is a 404 response
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// IsForbidden returns true when the error is a 403 response
+func IsForbidden(err error) bool {
+	return StatusCode(err) == http.StatusForbidden
+}
+
+// IsTooManyRequests returns true when the error is a 429 response
+func IsTooManyRequests(err error) bool {
+	return StatusCode(err) == http.StatusTooManyRequests
+}
+
+// IsServerError returns true when the error is a 5xx response
+func IsServerError(err error) bool {
+	code := StatusCode(err)
+	return code >= 500 && code < 600
+}
+
+// RetryAfter returns the delay requested by the Retry-After header of a 429 or 503 response
+func RetryAfter(err error) (time.Duration, bool) {
+	var serr *StatusError
+	if !errors.As(err, &serr) {
+		return 0, false
+	}
+
+	value := serr.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at), true
+	}
+	return 0, false
+}
@@ -0,0 +1,185 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTPOptions configures direct access to a backend
+type HTTPOptions struct {
+	// CAFile is the path to a PEM bundle of certificate authorities trusted in addition to the system ones
+	CAFile string
+
+	// Insecure skips the verification of the server certificate
+	Insecure bool
+
+	// BearerToken is sent in the Authorization header when set
+	BearerToken string
+
+	// Username and Password are sent using basic authentication when set
+	Username string
+	Password string
+
+	// Timeout is the maximum duration of a request, including reading the response.
+	// Zero means no timeout.
+	Timeout time.Duration
+}
+
+// HTTPClient gets resources from a backend reachable without going through the cluster
+type HTTPClient struct {
+	base    *url.URL
+	client  *http.Client
+	options HTTPOptions
+}
+
+var _ Client = (*HTTPClient)(nil)
+
+// NewHTTPClient creates a client for the backend rooted at the given URL
+func NewHTTPClient(baseURL string, options HTTPOptions) (*HTTPClient, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme in %q", baseURL)
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+
+	if options.BearerToken != "" && options.Username != "" {
+		return nil, errors.New("bearer token and basic authentication are mutually exclusive")
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: options.Insecure}
+	if options.CAFile != "" {
+		pem, err := os.ReadFile(options.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", options.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &HTTPClient{
+		base:    base,
+		client:  &http.Client{Transport: transport, Timeout: options.Timeout},
+		options: options,
+	}, nil
+}
+
+func (c *HTTPClient) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	target, err := c.base.Parse(strings.TrimPrefix(path, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	return get(ctx, c.client, target.String(), func(req *http.Request) {
+		switch {
+		case c.options.BearerToken != "":
+			req.Header.Set("Authorization", "Bearer "+c.options.BearerToken)
+		case c.options.Username != "":
+			req.SetBasicAuth(c.options.Username, c.options.Password)
+		}
+	})
+}
+
+func (c *HTTPClient) Close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+// resolveTimeout bounds the resolution of host names which may be public
+const resolveTimeout = 2 * time.Second
+
+// resolves returns true when the host name resolves from this machine
+var resolves = func(host string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	return err == nil && len(addrs) > 0
+}
+
+// resolved caches the resolutions of host names, as the same endpoint is
+// checked several times while connecting
+var resolved = struct {
+	sync.Mutex
+	hosts map[string]bool
+}{hosts: make(map[string]bool)}
+
+// cachedResolves returns true when the host name resolves, looking it up once per host
+func cachedResolves(host string) bool {
+	resolved.Lock()
+	defer resolved.Unlock()
+
+	ok, found := resolved.hosts[host]
+	if !found {
+		ok = resolves(host)
+		resolved.hosts[host] = ok
+	}
+	return ok
+}
+
+// IsClusterLocal returns true when the host can only be resolved from
+// within the cluster, e.g. "zipkin.istio-system" or "zipkin.istio-system.svc.cluster.local".
+// <service>.<namespace> hosts resolving from this machine, like "tracing.io", are public.
+func IsClusterLocal(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	if host == "localhost" || net.ParseIP(host) != nil {
+		return false
+	}
+
+	parts := strings.Split(host, ".")
+	switch {
+	case len(parts) == 1:
+		// <service>
+		return true
+	case len(parts) == 2:
+		// <service>.<namespace>, unless it is a public domain
+		return !cachedResolves(host)
+	case parts[2] == "svc":
+		// <service>.<namespace>.svc[.<cluster domain>]
+		return true
+	default:
+		return false
+	}
+}
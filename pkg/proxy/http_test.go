@@ -0,0 +1,121 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestIsClusterLocal(t *testing.T) {
+	public := map[string]bool{"tracing.io": true, "example.com": true}
+	lookups := make(map[string]int)
+	original := resolves
+	resolves = func(host string) bool {
+		lookups[host]++
+		return public[host]
+	}
+	defer func() { resolves = original }()
+
+	tests := []struct {
+		host  string
+		local bool
+	}{
+		{host: "zipkin", local: true},
+		{host: "zipkin.istio-system:9411", local: true},
+		{host: "zipkin.istio-system.svc", local: true},
+		{host: "zipkin.istio-system.svc.cluster.local:9411", local: true},
+		{host: "tracing.io:9411", local: false},
+		{host: "example.com", local: false},
+		{host: "zipkin.example.com", local: false},
+		{host: "localhost:9411", local: false},
+		{host: "10.0.0.1:9411", local: false},
+		{host: "[::1]:9411", local: false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, IsClusterLocal(tt.host), tt.local, tt.host)
+	}
+
+	// Hosts are looked up once
+	assert.Assert(t, IsClusterLocal("zipkin.istio-system"))
+	assert.Equal(t, lookups["zipkin.istio-system"], 1)
+}
+
+func TestHTTPClientGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "admin" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(r.URL.RequestURI()))
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(server.URL+"/zipkin", HTTPOptions{Username: "admin", Password: "secret"})
+	assert.NilError(t, err)
+
+	body, err := client.Get(context.Background(), "api/v2/services?limit=1")
+	assert.NilError(t, err)
+	defer body.Close()
+
+	content, err := io.ReadAll(body)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "/zipkin/api/v2/services?limit=1")
+
+	client, err = NewHTTPClient(server.URL, HTTPOptions{BearerToken: "token"})
+	assert.NilError(t, err)
+
+	_, err = client.Get(context.Background(), "api/v2/services")
+	assert.Equal(t, StatusCode(err), http.StatusUnauthorized)
+}
+
+func TestStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/trace/abc":
+			http.Error(w, "trace not found", http.StatusNotFound)
+		case "/api/v2/services":
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(server.URL, HTTPOptions{})
+	assert.NilError(t, err)
+
+	var v interface{}
+	err = GetJSON(context.Background(), client, "api/v2/trace/abc", &v)
+	assert.Assert(t, IsNotFound(err))
+	assert.Error(t, err, "404 Not Found: trace not found")
+
+	err = GetJSON(context.Background(), client, "api/v2/services", &v)
+	assert.Assert(t, IsTooManyRequests(err))
+	delay, ok := RetryAfter(err)
+	assert.Assert(t, ok)
+	assert.Equal(t, delay, 3*time.Second)
+
+	err = GetJSON(context.Background(), client, "api/v2/dependencies", &v)
+	assert.Assert(t, IsServerError(err))
+	assert.Assert(t, !IsForbidden(err))
+}
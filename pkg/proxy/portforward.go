@@ -0,0 +1,255 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// PortForwardClient gets resources from a Kubernetes service by port-forwarding
+// to one of its ready pods. The tunnel stays open until the client is closed
+// and is re-established on another pod when broken.
+type PortForwardClient struct {
+	cfg       *rest.Config
+	kube      kubernetes.Interface
+	name      string
+	port      string
+	namespace string
+
+	// reconnect serializes the re-establishment of broken tunnels
+	reconnect sync.Mutex
+
+	mu     sync.Mutex
+	stop   chan struct{}
+	client *HTTPClient
+	closed bool
+}
+
+var _ Client = (*PortForwardClient)(nil)
+
+// NewPortForwardClient opens a tunnel to a ready pod of the given service.
+// The name may include a port name or number, otherwise the first service port is used.
+func NewPortForwardClient(ctx context.Context, cfg *rest.Config, name, namespace string) (*PortForwardClient, error) {
+	kube, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &PortForwardClient{cfg: cfg, kube: kube, name: name, namespace: namespace}
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		c.name, c.port = name[:i], name[i+1:]
+	}
+
+	if err := c.open(ctx); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *PortForwardClient) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	c.mu.Lock()
+	client := c.client
+	c.mu.Unlock()
+
+	body, err := client.Get(ctx, path)
+	if err == nil || StatusCode(err) != 0 || ctx.Err() != nil {
+		return body, err
+	}
+
+	// The tunnel is broken, e.g. the pod is gone
+	client, openErr := c.reopen(ctx, client)
+	if openErr != nil {
+		return nil, fmt.Errorf("%w (reconnecting failed: %v)", err, openErr)
+	}
+	return client.Get(ctx, path)
+}
+
+// reopen replaces the tunnel of the broken client, unless another request already did
+func (c *PortForwardClient) reopen(ctx context.Context, broken *HTTPClient) (*HTTPClient, error) {
+	c.reconnect.Lock()
+	defer c.reconnect.Unlock()
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, errors.New("port-forward closed")
+	}
+	if c.client != broken {
+		client := c.client
+		c.mu.Unlock()
+		return client, nil
+	}
+	c.closeTunnel()
+	c.mu.Unlock()
+
+	if err := c.open(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.client, nil
+}
+
+// Close closes the tunnel
+func (c *PortForwardClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	c.closeTunnel()
+	return nil
+}
+
+// closeTunnel stops port-forwarding. The caller must hold the lock.
+func (c *PortForwardClient) closeTunnel() {
+	if c.stop != nil {
+		close(c.stop)
+		c.stop = nil
+	}
+	if c.client != nil {
+		c.client.Close()
+	}
+}
+
+func (c *PortForwardClient) open(ctx context.Context) error {
+	pod, port, err := c.target(ctx)
+	if err != nil {
+		return err
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(c.cfg)
+	if err != nil {
+		return err
+	}
+
+	req := c.kube.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(c.namespace).
+		Name(pod).
+		SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+
+	stop := make(chan struct{})
+	ready := make(chan struct{})
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"localhost"}, []string{fmt.Sprintf("0:%d", port)}, stop, ready, io.Discard, io.Discard)
+	if err != nil {
+		return err
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- forwarder.ForwardPorts()
+	}()
+
+	select {
+	case <-ready:
+	case <-ctx.Done():
+		close(stop)
+		return ctx.Err()
+	case err := <-errCh:
+		return fmt.Errorf("port-forwarding to pod %s/%s failed: %w", c.namespace, pod, err)
+	}
+
+	ports, err := forwarder.GetPorts()
+	if err != nil {
+		close(stop)
+		return err
+	}
+
+	client, err := NewHTTPClient(fmt.Sprintf("http://localhost:%d", ports[0].Local), HTTPOptions{Timeout: c.cfg.Timeout})
+	if err != nil {
+		close(stop)
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		close(stop)
+		return errors.New("port-forward closed")
+	}
+	c.stop = stop
+	c.client = client
+	return nil
+}
+
+// target returns a ready pod behind the service and the container port to forward to
+func (c *PortForwardClient) target(ctx context.Context) (string, int, error) {
+	svc, err := c.kube.CoreV1().Services(c.namespace).Get(ctx, c.name, metav1.GetOptions{})
+	if err != nil {
+		return "", 0, err
+	}
+
+	svcPort, err := c.servicePort(svc)
+	if err != nil {
+		return "", 0, err
+	}
+
+	// Endpoints only list ready pods, with the resolved target ports
+	endpoints, err := c.kube.CoreV1().Endpoints(c.namespace).Get(ctx, c.name, metav1.GetOptions{})
+	if err != nil {
+		return "", 0, err
+	}
+
+	for _, subset := range endpoints.Subsets {
+		port := int32(0)
+		for _, p := range subset.Ports {
+			if p.Name == svcPort.Name {
+				port = p.Port
+			}
+		}
+		if port == 0 {
+			continue
+		}
+
+		for _, address := range subset.Addresses {
+			if address.TargetRef != nil && address.TargetRef.Kind == "Pod" {
+				return address.TargetRef.Name, int(port), nil
+			}
+		}
+	}
+	return "", 0, fmt.Errorf("no ready pod behind service %s/%s", c.namespace, c.name)
+}
+
+func (c *PortForwardClient) servicePort(svc *corev1.Service) (corev1.ServicePort, error) {
+	if len(svc.Spec.Ports) == 0 {
+		return corev1.ServicePort{}, fmt.Errorf("service %s/%s has no ports", c.namespace, c.name)
+	}
+	if c.port == "" {
+		return svc.Spec.Ports[0], nil
+	}
+
+	for _, port := range svc.Spec.Ports {
+		if port.Name == c.port || strconv.Itoa(int(port.Port)) == c.port {
+			return port, nil
+		}
+	}
+	return corev1.ServicePort{}, fmt.Errorf("service %s/%s has no port %s", c.namespace, c.name, c.port)
+}
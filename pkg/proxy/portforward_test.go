@@ -0,0 +1,59 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPortForwardTarget(t *testing.T) {
+	meta := metav1.ObjectMeta{Name: "jaeger-query", Namespace: "observability"}
+	kube := fake.NewSimpleClientset(
+		&corev1.Service{
+			ObjectMeta: meta,
+			Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{
+				{Name: "grpc", Port: 16685},
+				{Name: "http", Port: 16686, TargetPort: intstr.FromString("query")},
+			}},
+		},
+		&corev1.Endpoints{
+			ObjectMeta: meta,
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{{
+					IP:        "10.0.0.1",
+					TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "jaeger-7d9f"},
+				}},
+				Ports: []corev1.EndpointPort{{Name: "grpc", Port: 16685}, {Name: "http", Port: 8080}},
+			}},
+		},
+	)
+
+	c := &PortForwardClient{kube: kube, name: "jaeger-query", port: "16686", namespace: "observability"}
+	pod, port, err := c.target(context.Background())
+	assert.NilError(t, err)
+	assert.Equal(t, pod, "jaeger-7d9f")
+	assert.Equal(t, port, 8080)
+
+	c.port = "metrics"
+	_, _, err = c.target(context.Background())
+	assert.ErrorContains(t, err, "has no port metrics")
+}
@@ -15,45 +15,122 @@
 package proxy
 
 import (
-	"errors"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
-	"net/http/httptest"
+	"net/url"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/rest"
-
-	kubeproxy "k8s.io/kubectl/pkg/proxy"
 )
 
+// Client gets resources from a tracing backend
+type Client interface {
+	// Get returns the body of the resource at the given path, relative to the backend root.
+	// The caller must close the body. Unexpected responses are reported as *StatusError.
+	Get(ctx context.Context, path string) (io.ReadCloser, error)
+
+	// Close releases the connections to the backend
+	Close() error
+}
+
+// GetJSON decodes the JSON resource at the given path into v, without buffering the response
+func GetJSON(ctx context.Context, client Client, path string, v interface{}) error {
+	body, err := client.Get(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	return json.NewDecoder(body).Decode(v)
+}
+
+// Proxy gets resources from Kubernetes services through the API server service proxy
 type Proxy struct {
-	handler http.Handler
+	client *http.Client
+	host   *url.URL
 }
 
 // New creates a Kubernetes service proxy
 func New(cfg *rest.Config) (Proxy, error) {
-	handler, err := kubeproxy.NewProxyHandler("/", nil, cfg, 0)
+	transport, err := rest.TransportFor(cfg)
 	if err != nil {
 		return Proxy{}, err
 	}
 
-	return Proxy{handler: handler}, nil
+	host, _, err := rest.DefaultServerURL(cfg.Host, "", schema.GroupVersion{}, rest.IsConfigTransportTLS(*cfg))
+	if err != nil {
+		return Proxy{}, err
+	}
+
+	return Proxy{client: &http.Client{Transport: transport, Timeout: cfg.Timeout}, host: host}, nil
 }
 
-func (p Proxy) Get(name, namespace, path string) (string, error) {
-	target := makeURL(name, namespace, path)
-	req := httptest.NewRequest("GET", target, nil)
-	responseRecorder := httptest.NewRecorder()
+func (p Proxy) Get(ctx context.Context, name, namespace, path string) (io.ReadCloser, error) {
+	target := *p.host
+	target.Path = strings.TrimSuffix(target.Path, "/") + makeURL(name, namespace, "")
 
-	p.handler.ServeHTTP(responseRecorder, req)
-	body := responseRecorder.Body.String()
+	rel, err := url.Parse(strings.TrimPrefix(path, "/"))
+	if err != nil {
+		return nil, err
+	}
+	target.Path += "/" + rel.Path
+	target.RawQuery = rel.RawQuery
+
+	return get(ctx, p.client, target.String(), nil)
+}
 
-	if responseRecorder.Code != http.StatusOK {
-		return "", errors.New(body)
+// ServiceClient gets resources from a Kubernetes service through the proxy
+type ServiceClient struct {
+	proxy     Proxy
+	name      string
+	namespace string
+}
+
+var _ Client = ServiceClient{}
+
+// NewServiceClient creates a client for the given service. The name may include a port.
+func NewServiceClient(cfg *rest.Config, name, namespace string) (ServiceClient, error) {
+	proxy, err := New(cfg)
+	if err != nil {
+		return ServiceClient{}, err
+	}
+	return ServiceClient{proxy: proxy, name: name, namespace: namespace}, nil
+}
+
+func (c ServiceClient) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	return c.proxy.Get(ctx, c.name, c.namespace, path)
+}
+
+func (c ServiceClient) Close() error {
+	c.proxy.client.CloseIdleConnections()
+	return nil
+}
+
+// get sends a GET request and returns the response body when the status is 200
+func get(ctx context.Context, client *http.Client, target string, decorate func(req *http.Request)) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if decorate != nil {
+		decorate(req)
 	}
 
-	return body, nil
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
 
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, newStatusError(resp)
+	}
+	return resp.Body, nil
 }
 
 func makeURL(name, namespace, path string) string {
@@ -0,0 +1,147 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"k8s.io/client-go/rest"
+)
+
+// Transports to reach backends running in the cluster
+const (
+	// TransportAuto uses the service proxy, switching to port-forwarding when the proxy is forbidden
+	TransportAuto = "auto"
+
+	// TransportProxy goes through the API server service proxy
+	TransportProxy = "proxy"
+
+	// TransportPortForward port-forwards to a ready pod behind the service
+	TransportPortForward = "port-forward"
+)
+
+// Options configures how backends are reached
+type Options struct {
+	// Transport to reach backends running in the cluster
+	Transport string
+
+	// HTTP configures the access to backends reachable from outside the cluster
+	HTTP HTTPOptions
+}
+
+// NewClient creates a client for the given service using the given transport.
+// The name may include a port.
+func NewClient(ctx context.Context, cfg *rest.Config, name, namespace, transport string) (Client, error) {
+	switch transport {
+	case TransportProxy:
+		client, err := NewServiceClient(cfg, name, namespace)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	case TransportPortForward:
+		client, err := NewPortForwardClient(ctx, cfg, name, namespace)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	case TransportAuto, "":
+		client, err := NewServiceClient(cfg, name, namespace)
+		if err != nil {
+			return nil, err
+		}
+		return &autoClient{cfg: cfg, name: name, namespace: namespace, client: client}, nil
+	default:
+		return nil, fmt.Errorf("invalid transport %q. Must be one of: auto, proxy, port-forward", transport)
+	}
+}
+
+// autoClient uses the service proxy until it is forbidden, then port-forwards
+type autoClient struct {
+	cfg       *rest.Config
+	name      string
+	namespace string
+
+	// fallback serializes the switch to port-forwarding
+	fallback sync.Mutex
+
+	mu     sync.Mutex
+	client Client
+	closed bool
+}
+
+func (c *autoClient) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	c.mu.Lock()
+	client := c.client
+	c.mu.Unlock()
+
+	body, err := client.Get(ctx, path)
+	if err == nil || !IsForbidden(err) {
+		return body, err
+	}
+	if _, ok := client.(*PortForwardClient); ok {
+		return nil, err
+	}
+
+	forwarded, pfErr := c.portForward(ctx, client)
+	if pfErr != nil {
+		return nil, fmt.Errorf("%w (port-forward fallback failed: %v)", err, pfErr)
+	}
+	return forwarded.Get(ctx, path)
+}
+
+// portForward switches from the forbidden client to port-forwarding, unless
+// another request already did
+func (c *autoClient) portForward(ctx context.Context, forbidden Client) (Client, error) {
+	c.fallback.Lock()
+	defer c.fallback.Unlock()
+
+	c.mu.Lock()
+	current, closed := c.client, c.closed
+	c.mu.Unlock()
+	if closed {
+		return nil, errors.New("client closed")
+	}
+	if current != forbidden {
+		return current, nil
+	}
+
+	forwarded, err := NewPortForwardClient(ctx, c.cfg, c.name, c.namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		forwarded.Close()
+		return nil, errors.New("client closed")
+	}
+	forbidden.Close()
+	c.client = forwarded
+	return forwarded, nil
+}
+
+func (c *autoClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	return c.client.Close()
+}
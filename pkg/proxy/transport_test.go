@@ -0,0 +1,50 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// forbiddenClient is a client whose requests are all forbidden
+type forbiddenClient struct {
+	closed bool
+}
+
+func (c *forbiddenClient) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	return nil, &StatusError{StatusCode: http.StatusForbidden, Status: "403 Forbidden"}
+}
+
+func (c *forbiddenClient) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestAutoClientClose(t *testing.T) {
+	inner := &forbiddenClient{}
+	client := &autoClient{name: "zipkin", namespace: "kntools", client: inner}
+
+	assert.NilError(t, client.Close())
+	assert.Assert(t, inner.closed)
+
+	// No tunnel is opened once closed
+	_, err := client.Get(context.Background(), "api/v2/services")
+	assert.ErrorContains(t, err, "client closed")
+}
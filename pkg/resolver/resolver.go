@@ -0,0 +1,288 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package resolver maps spans to the Knative Eventing objects they belong to
+package resolver
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+
+	clientdynamic "knative.dev/client/pkg/dynamic"
+	clienteventingv1 "knative.dev/client/pkg/eventing/v1"
+	clientmessagingv1 "knative.dev/client/pkg/messaging/v1"
+)
+
+// Tags set by the OpenCensus HTTP instrumentation of Knative components
+const (
+	httpHostTag = "http.host"
+	httpURLTag  = "http.url"
+)
+
+// knownSources maps the resources of the core sources to their kind,
+// for when sources cannot be listed
+var knownSources = map[string]string{
+	"apiserversources": "ApiServerSource",
+	"containersources": "ContainerSource",
+	"pingsources":      "PingSource",
+	"sinkbindings":     "SinkBinding",
+}
+
+// Clients are the clients used to list Knative Eventing objects. Any of them may be nil.
+type Clients struct {
+	Eventing  clienteventingv1.KnEventingClient
+	Messaging clientmessagingv1.KnMessagingClient
+	Dynamic   clientdynamic.KnDynamicClient
+}
+
+// Resolver maps spans to Knative Eventing objects.
+//
+// Broker and Trigger spans are resolved from their names. Channels,
+// Subscriptions and Sources are resolved from the objects loaded from
+// the cluster.
+type Resolver struct {
+	// brokers of triggers, indexed by trigger
+	brokers map[trace.Resource]string
+
+	// channels are indexed by the host of their address
+	channels map[string]trace.Resource
+
+	// subscriptions are indexed by subscriber URI
+	subscriptions map[string][]trace.Resource
+
+	// sources are indexed by CloudEvent source attribute
+	sources map[string]trace.Resource
+}
+
+// New creates a resolver which does not know any object
+func New() *Resolver {
+	return &Resolver{
+		brokers:       make(map[trace.Resource]string),
+		channels:      make(map[string]trace.Resource),
+		subscriptions: make(map[string][]trace.Resource),
+		sources:       make(map[string]trace.Resource),
+	}
+}
+
+// Load indexes the Triggers, Channels, Subscriptions and Sources of all namespaces.
+// Objects which cannot be listed are reported in the returned error,
+// the others are still indexed.
+func (r *Resolver) Load(ctx context.Context, clients Clients) error {
+	var errs []error
+
+	if clients.Eventing != nil {
+		triggers, err := clients.Eventing.ListTriggers(ctx)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			for _, t := range triggers.Items {
+				r.addTrigger(trace.Resource{Kind: "Trigger", Namespace: t.Namespace, Name: t.Name}, t.Spec.Broker)
+			}
+		}
+	}
+
+	if clients.Messaging != nil {
+		channels, err := clients.Messaging.ChannelsClient().ListChannel(ctx)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			for _, ch := range channels.Items {
+				if ch.Status.Address != nil && ch.Status.Address.URL != nil {
+					r.addChannel(ch.Status.Address.URL.Host, trace.Resource{Kind: "Channel", Namespace: ch.Namespace, Name: ch.Name})
+				}
+			}
+		}
+
+		subscriptions, err := clients.Messaging.SubscriptionsClient().ListSubscription(ctx)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			for _, sub := range subscriptions.Items {
+				if uri := sub.Status.PhysicalSubscription.SubscriberURI; uri != nil {
+					r.addSubscription(uri.String(), trace.Resource{Kind: "Subscription", Namespace: sub.Namespace, Name: sub.Name})
+				}
+			}
+		}
+	}
+
+	if clients.Dynamic != nil {
+		sources, err := clients.Dynamic.ListSources(ctx)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			for _, source := range sources.Items {
+				kind := source.GetKind()
+				r.addSource(sourceAttribute(source.GetNamespace(), strings.ToLower(kind)+"s", source.GetName()),
+					trace.Resource{Kind: kind, Namespace: source.GetNamespace(), Name: source.GetName()})
+			}
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func (r *Resolver) addTrigger(trigger trace.Resource, broker string) {
+	r.brokers[trigger] = broker
+}
+
+func (r *Resolver) addChannel(host string, channel trace.Resource) {
+	r.channels[stripPort(host)] = channel
+}
+
+func (r *Resolver) addSubscription(subscriberURI string, subscription trace.Resource) {
+	r.subscriptions[subscriberURI] = append(r.subscriptions[subscriberURI], subscription)
+}
+
+func (r *Resolver) addSource(attribute string, source trace.Resource) {
+	r.sources[attribute] = source
+}
+
+// Resolve returns the object the span belongs to, or nil when unknown
+func (r *Resolver) Resolve(span trace.Span) *trace.Resource {
+	destination := span.Tags[trace.MessagingDestinationTag]
+	for _, kind := range []string{"Broker", "Trigger"} {
+		prefix := strings.ToLower(kind) + ":"
+		for _, name := range []string{span.Name, destination} {
+			if strings.HasPrefix(name, prefix) {
+				if res, ok := parseDestination(kind, strings.TrimPrefix(name, prefix)); ok {
+					return &res
+				}
+			}
+		}
+	}
+
+	if host, ok := span.Tags[httpHostTag]; ok && span.Kind != trace.KindClient {
+		if res, ok := r.channels[stripPort(host)]; ok {
+			return &res
+		}
+	}
+
+	// Subscribers called by channel dispatchers
+	if target, ok := span.Tags[httpURLTag]; ok && span.Kind == trace.KindClient {
+		if subs := r.subscriptions[target]; len(subs) == 1 {
+			return &subs[0]
+		}
+	}
+
+	if trace.Component(span) == trace.ComponentSource {
+		if attribute, ok := span.Tags[trace.CloudEventSourceTag]; ok {
+			if res, ok := r.sources[attribute]; ok {
+				return &res
+			}
+			if res, ok := parseSourceAttribute(attribute); ok {
+				return &res
+			}
+		}
+	}
+	return nil
+}
+
+// Annotate sets the resource of the given spans which can be resolved
+func (r *Resolver) Annotate(spans []trace.Span) {
+	for i := range spans {
+		if spans[i].Resource == nil {
+			spans[i].Resource = r.Resolve(spans[i])
+		}
+	}
+}
+
+// Match returns true when the resource has the given kind and is named
+// either <name> or <namespace>/<name>
+func Match(res *trace.Resource, kind, name string) bool {
+	if res == nil || res.Kind != kind {
+		return false
+	}
+	if ns, n, ok := cut(name, "/"); ok {
+		return res.Namespace == ns && res.Name == n
+	}
+	return res.Name == name
+}
+
+// MatchBroker returns true when the resource is the given Broker or one of its
+// Triggers. The broker is named either <name> or <namespace>/<name>.
+func (r *Resolver) MatchBroker(res *trace.Resource, name string) bool {
+	if Match(res, "Broker", name) {
+		return true
+	}
+	if res == nil || res.Kind != "Trigger" {
+		return false
+	}
+	broker, ok := r.brokers[*res]
+	return ok && Match(&trace.Resource{Kind: "Broker", Namespace: res.Namespace, Name: broker}, "Broker", name)
+}
+
+// MatchSource returns true when the resource is a Source named either
+// <name> or <namespace>/<name>
+func MatchSource(res *trace.Resource, name string) bool {
+	if res == nil || !IsSource(res.Kind) {
+		return false
+	}
+	return Match(res, res.Kind, name)
+}
+
+// IsSource returns true when the kind is not one of the Eventing core objects
+func IsSource(kind string) bool {
+	switch kind {
+	case "Broker", "Trigger", "Channel", "Subscription":
+		return false
+	}
+	return kind != ""
+}
+
+// parseDestination parses the "<name>.<namespace>" destinations of Broker and Trigger spans.
+// Namespaces cannot contain dots, unlike names.
+func parseDestination(kind, destination string) (trace.Resource, bool) {
+	i := strings.LastIndex(destination, ".")
+	if i <= 0 || i == len(destination)-1 {
+		return trace.Resource{}, false
+	}
+	return trace.Resource{Kind: kind, Namespace: destination[i+1:], Name: destination[:i]}, true
+}
+
+// sourceAttribute returns the CloudEvent source attribute set by the core sources
+func sourceAttribute(namespace, resource, name string) string {
+	return "/apis/v1/namespaces/" + namespace + "/" + resource + "/" + name
+}
+
+// parseSourceAttribute resolves the CloudEvent source attribute of the core sources
+func parseSourceAttribute(attribute string) (trace.Resource, bool) {
+	parts := strings.Split(strings.TrimPrefix(attribute, "/apis/v1/namespaces/"), "/")
+	if len(parts) != 3 || !strings.HasPrefix(attribute, "/apis/v1/namespaces/") {
+		return trace.Resource{}, false
+	}
+
+	kind, ok := knownSources[parts[1]]
+	if !ok {
+		return trace.Resource{}, false
+	}
+	return trace.Resource{Kind: kind, Namespace: parts[0], Name: parts[2]}, true
+}
+
+func stripPort(host string) string {
+	if u, err := url.Parse("//" + host); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return host
+}
+
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
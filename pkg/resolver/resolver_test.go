@@ -0,0 +1,89 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolver
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+func TestResolve(t *testing.T) {
+	r := New()
+	r.addChannel("demo-kn-channel.default.svc.cluster.local:80", trace.Resource{Kind: "Channel", Namespace: "default", Name: "demo"})
+	r.addSubscription("http://event-display.default.svc.cluster.local", trace.Resource{Kind: "Subscription", Namespace: "default", Name: "display"})
+	r.addSource(sourceAttribute("default", "kafkasources", "orders"), trace.Resource{Kind: "KafkaSource", Namespace: "default", Name: "orders"})
+
+	adapter := &trace.Endpoint{ServiceName: "kafkasource-mt-adapter"}
+
+	for _, tc := range []struct {
+		name     string
+		span     trace.Span
+		expected *trace.Resource
+	}{{
+		name:     "broker",
+		span:     trace.Span{Name: "broker:default.my.namespace"},
+		expected: &trace.Resource{Kind: "Broker", Namespace: "namespace", Name: "default.my"},
+	}, {
+		name:     "trigger",
+		span:     trace.Span{Name: "knative.dev", Tags: map[string]string{trace.MessagingDestinationTag: "trigger:display.default"}},
+		expected: &trace.Resource{Kind: "Trigger", Namespace: "default", Name: "display"},
+	}, {
+		name:     "channel",
+		span:     trace.Span{Kind: trace.KindServer, Tags: map[string]string{httpHostTag: "demo-kn-channel.default.svc.cluster.local"}},
+		expected: &trace.Resource{Kind: "Channel", Namespace: "default", Name: "demo"},
+	}, {
+		name:     "subscription",
+		span:     trace.Span{Kind: trace.KindClient, Tags: map[string]string{httpURLTag: "http://event-display.default.svc.cluster.local"}},
+		expected: &trace.Resource{Kind: "Subscription", Namespace: "default", Name: "display"},
+	}, {
+		name:     "listed source",
+		span:     trace.Span{LocalEndpoint: adapter, Tags: map[string]string{trace.CloudEventSourceTag: "/apis/v1/namespaces/default/kafkasources/orders"}},
+		expected: &trace.Resource{Kind: "KafkaSource", Namespace: "default", Name: "orders"},
+	}, {
+		name:     "core source",
+		span:     trace.Span{LocalEndpoint: adapter, Tags: map[string]string{trace.CloudEventSourceTag: "/apis/v1/namespaces/default/pingsources/tick"}},
+		expected: &trace.Resource{Kind: "PingSource", Namespace: "default", Name: "tick"},
+	}, {
+		name: "event received by a subscriber",
+		span: trace.Span{Kind: trace.KindServer, LocalEndpoint: &trace.Endpoint{ServiceName: "event-display"}, Tags: map[string]string{trace.CloudEventSourceTag: "/apis/v1/namespaces/default/pingsources/tick"}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.DeepEqual(t, r.Resolve(tc.span), tc.expected)
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	res := &trace.Resource{Kind: "Broker", Namespace: "default", Name: "demo"}
+	assert.Assert(t, Match(res, "Broker", "demo"))
+	assert.Assert(t, Match(res, "Broker", "default/demo"))
+	assert.Assert(t, !Match(res, "Broker", "other/demo"))
+	assert.Assert(t, !Match(res, "Trigger", "demo"))
+	assert.Assert(t, !Match(nil, "Broker", "demo"))
+}
+
+func TestMatchBroker(t *testing.T) {
+	r := New()
+	r.addTrigger(trace.Resource{Kind: "Trigger", Namespace: "default", Name: "display"}, "demo")
+
+	assert.Assert(t, r.MatchBroker(&trace.Resource{Kind: "Broker", Namespace: "default", Name: "demo"}, "demo"))
+	assert.Assert(t, r.MatchBroker(&trace.Resource{Kind: "Trigger", Namespace: "default", Name: "display"}, "default/demo"))
+	assert.Assert(t, !r.MatchBroker(&trace.Resource{Kind: "Trigger", Namespace: "default", Name: "other"}, "demo"))
+
+	assert.Assert(t, MatchSource(&trace.Resource{Kind: "PingSource", Namespace: "default", Name: "tick"}, "tick"))
+	assert.Assert(t, !MatchSource(&trace.Resource{Kind: "Broker", Namespace: "default", Name: "tick"}, "tick"))
+}
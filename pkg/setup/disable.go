@@ -0,0 +1,299 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package setup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/kn-plugin-trace/pkg/config"
+)
+
+// Disable sets the tracing backend of the given targets to none. When restore
+// is true, the settings recorded when kn trace enabled tracing are restored.
+func Disable(ctx context.Context, client kubernetes.Interface, targets []config.Target, restore bool) error {
+	for _, target := range targets {
+		_, err := client.CoreV1().ConfigMaps(target.Namespace).Get(ctx, config.ConfigMapName, metav1.GetOptions{})
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			fmt.Printf("tracing of %s is already disabled\n", target)
+			continue
+		}
+
+		updated, err := config.Write(ctx, client, target, func(cm *corev1.ConfigMap) (bool, error) {
+			return disable(cm, restore)
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", target, err)
+		}
+
+		if !updated {
+			fmt.Printf("tracing of %s is already disabled\n", target)
+			continue
+		}
+		fmt.Printf("tracing of %s disabled\n", target)
+	}
+	return nil
+}
+
+// disable sets the backend of the ConfigMap to none and restores the recorded settings
+func disable(cm *corev1.ConfigMap, restore bool) (bool, error) {
+	updated := false
+	if cm.Data[config.BackendKey] != "none" {
+		cm.Data[config.BackendKey] = "none"
+		updated = true
+	}
+
+	previous, ok := cm.Annotations[config.PreviousAnnotation]
+	if !ok || !restore {
+		return updated, nil
+	}
+
+	var settings map[string]string
+	if err := json.Unmarshal([]byte(previous), &settings); err != nil {
+		return false, fmt.Errorf("invalid %s annotation: %w", config.PreviousAnnotation, err)
+	}
+
+	for _, key := range restorable {
+		if value, ok := settings[key]; ok {
+			cm.Data[key] = value
+		} else {
+			delete(cm.Data, key)
+		}
+	}
+	delete(cm.Annotations, config.PreviousAnnotation)
+	return true, nil
+}
+
+// UninstallZipkin removes the Zipkin installed by kn trace. Objects which are
+// not labeled as managed by kn trace are left untouched. It fails when one of
+// the given targets still sends spans to Zipkin.
+func UninstallZipkin(ctx context.Context, client kubernetes.Interface, targets []config.Target) error {
+	for _, target := range targets {
+		cfg, err := config.Load(ctx, client, target)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if cfg.Backend == "zipkin" && cfg.ZipkinEndpoint == ZipkinEndpoint {
+			return fmt.Errorf("zipkin is still used by %s", target)
+		}
+	}
+
+	deployment, err := client.AppsV1().Deployments(KnToolsNamespace).Get(ctx, "zipkin", metav1.GetOptions{})
+	if err == nil {
+		if managed(deployment) {
+			if err := client.AppsV1().Deployments(KnToolsNamespace).Delete(ctx, "zipkin", metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+			fmt.Printf("deployment %s/zipkin deleted\n", KnToolsNamespace)
+		} else {
+			fmt.Printf("⚠️ deployment %s/zipkin is not managed by kn trace (skipped)\n", KnToolsNamespace)
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	service, err := client.CoreV1().Services(KnToolsNamespace).Get(ctx, "zipkin", metav1.GetOptions{})
+	if err == nil {
+		if managed(service) {
+			if err := client.CoreV1().Services(KnToolsNamespace).Delete(ctx, "zipkin", metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+			fmt.Printf("service %s/zipkin deleted\n", KnToolsNamespace)
+		} else {
+			fmt.Printf("⚠️ service %s/zipkin is not managed by kn trace (skipped)\n", KnToolsNamespace)
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	return deleteNamespace(ctx, client)
+}
+
+// deleteNamespace deletes the kntools namespace when kn trace created it and
+// it holds no object managed by someone else
+func deleteNamespace(ctx context.Context, client kubernetes.Interface) error {
+	ns, err := client.CoreV1().Namespaces().Get(ctx, KnToolsNamespace, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !managed(ns) {
+		fmt.Printf("⚠️ namespace %s is not managed by kn trace (skipped)\n", KnToolsNamespace)
+		return nil
+	}
+
+	kind, name, err := foreignObject(ctx, client, KnToolsNamespace)
+	if err != nil {
+		return err
+	}
+	if kind != "" {
+		fmt.Printf("⚠️ namespace %s contains %s %s (skipped)\n", KnToolsNamespace, kind, name)
+		return nil
+	}
+
+	if err := client.CoreV1().Namespaces().Delete(ctx, KnToolsNamespace, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	fmt.Printf("namespace %s deleted\n", KnToolsNamespace)
+	return nil
+}
+
+// kindObject is an object along with its kind, which typed objects don't carry
+type kindObject struct {
+	kind string
+	obj  metav1.Object
+}
+
+// foreignObject returns the kind and name of an object of the namespace which
+// is not managed by kn trace, or an empty kind when there is none. Objects
+// controlled by another object, such as Pods of a Deployment, and the ones
+// Kubernetes creates in every namespace are not considered.
+func foreignObject(ctx context.Context, client kubernetes.Interface, namespace string) (string, string, error) {
+	var objects []kindObject
+	add := func(kind string, obj metav1.Object) {
+		objects = append(objects, kindObject{kind: kind, obj: obj})
+	}
+	opts := metav1.ListOptions{}
+
+	deployments, err := client.AppsV1().Deployments(namespace).List(ctx, opts)
+	if err != nil {
+		return "", "", err
+	}
+	for i := range deployments.Items {
+		add("deployment", &deployments.Items[i])
+	}
+
+	statefulSets, err := client.AppsV1().StatefulSets(namespace).List(ctx, opts)
+	if err != nil {
+		return "", "", err
+	}
+	for i := range statefulSets.Items {
+		add("statefulset", &statefulSets.Items[i])
+	}
+
+	daemonSets, err := client.AppsV1().DaemonSets(namespace).List(ctx, opts)
+	if err != nil {
+		return "", "", err
+	}
+	for i := range daemonSets.Items {
+		add("daemonset", &daemonSets.Items[i])
+	}
+
+	replicaSets, err := client.AppsV1().ReplicaSets(namespace).List(ctx, opts)
+	if err != nil {
+		return "", "", err
+	}
+	for i := range replicaSets.Items {
+		add("replicaset", &replicaSets.Items[i])
+	}
+
+	jobs, err := client.BatchV1().Jobs(namespace).List(ctx, opts)
+	if err != nil {
+		return "", "", err
+	}
+	for i := range jobs.Items {
+		add("job", &jobs.Items[i])
+	}
+
+	cronJobs, err := client.BatchV1().CronJobs(namespace).List(ctx, opts)
+	if err != nil {
+		return "", "", err
+	}
+	for i := range cronJobs.Items {
+		add("cronjob", &cronJobs.Items[i])
+	}
+
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, opts)
+	if err != nil {
+		return "", "", err
+	}
+	for i := range pods.Items {
+		add("pod", &pods.Items[i])
+	}
+
+	services, err := client.CoreV1().Services(namespace).List(ctx, opts)
+	if err != nil {
+		return "", "", err
+	}
+	for i := range services.Items {
+		add("service", &services.Items[i])
+	}
+
+	configMaps, err := client.CoreV1().ConfigMaps(namespace).List(ctx, opts)
+	if err != nil {
+		return "", "", err
+	}
+	for i := range configMaps.Items {
+		// Published in every namespace by Kubernetes
+		if configMaps.Items[i].Name != "kube-root-ca.crt" {
+			add("configmap", &configMaps.Items[i])
+		}
+	}
+
+	secrets, err := client.CoreV1().Secrets(namespace).List(ctx, opts)
+	if err != nil {
+		return "", "", err
+	}
+	for i := range secrets.Items {
+		if secrets.Items[i].Type != corev1.SecretTypeServiceAccountToken {
+			add("secret", &secrets.Items[i])
+		}
+	}
+
+	serviceAccounts, err := client.CoreV1().ServiceAccounts(namespace).List(ctx, opts)
+	if err != nil {
+		return "", "", err
+	}
+	for i := range serviceAccounts.Items {
+		if serviceAccounts.Items[i].Name != "default" {
+			add("serviceaccount", &serviceAccounts.Items[i])
+		}
+	}
+
+	claims, err := client.CoreV1().PersistentVolumeClaims(namespace).List(ctx, opts)
+	if err != nil {
+		return "", "", err
+	}
+	for i := range claims.Items {
+		add("persistentvolumeclaim", &claims.Items[i])
+	}
+
+	for _, o := range objects {
+		if managed(o.obj) || metav1.GetControllerOf(o.obj) != nil {
+			continue
+		}
+		return o.kind, o.obj.GetName(), nil
+	}
+	return "", "", nil
+}
+
+// managed returns true when the object has been created by kn trace
+func managed(obj metav1.Object) bool {
+	return obj.GetLabels()[ManagedByLabel] == ManagedBy
+}
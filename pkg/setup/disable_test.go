@@ -0,0 +1,121 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package setup
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/kn-plugin-trace/internal/config/fake"
+	"knative.dev/kn-plugin-trace/pkg/config"
+)
+
+func TestDisableRestore(t *testing.T) {
+	ctx := context.Background()
+	target := config.Target{Component: config.Eventing, Namespace: "knative-eventing"}
+
+	client := fake.NewClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: config.ConfigMapName, Namespace: target.Namespace},
+		Data:       map[string]string{"sample-rate": "0.1"},
+	})
+
+	// Settings are written by kn trace when enabling tracing
+	_, err := config.Write(ctx, client, target, func(cm *corev1.ConfigMap) (bool, error) {
+		if err := recordPrevious(cm); err != nil {
+			return false, err
+		}
+		cm.Data["backend"] = "zipkin"
+		cm.Data["debug"] = "true"
+		return true, nil
+	})
+	assert.NilError(t, err)
+
+	assert.NilError(t, Disable(ctx, client, []config.Target{target}, true))
+
+	cm, err := client.CoreV1().ConfigMaps(target.Namespace).Get(ctx, config.ConfigMapName, metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, cm.Data, map[string]string{"backend": "none", "sample-rate": "0.1"})
+	_, ok := cm.Annotations[config.PreviousAnnotation]
+	assert.Assert(t, !ok)
+}
+
+func TestUninstallZipkin(t *testing.T) {
+	ctx := context.Background()
+	managedBy := map[string]string{ManagedByLabel: ManagedBy}
+
+	client := fake.NewClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: KnToolsNamespace, Labels: managedBy}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "zipkin", Namespace: KnToolsNamespace, Labels: managedBy}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "zipkin", Namespace: KnToolsNamespace, Labels: managedBy}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: KnToolsNamespace}},
+	)
+	assert.NilError(t, UninstallZipkin(ctx, client, nil))
+
+	_, err := client.AppsV1().Deployments(KnToolsNamespace).Get(ctx, "zipkin", metav1.GetOptions{})
+	assert.Assert(t, apierrors.IsNotFound(err))
+
+	// The namespace holds a workload kn trace does not manage
+	_, err = client.CoreV1().Namespaces().Get(ctx, KnToolsNamespace, metav1.GetOptions{})
+	assert.NilError(t, err)
+
+	// User data in the namespace keeps it alive
+	for _, obj := range []runtime.Object{
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: KnToolsNamespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: KnToolsNamespace}},
+	} {
+		client = fake.NewClientset(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: KnToolsNamespace, Labels: managedBy}},
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "zipkin", Namespace: KnToolsNamespace, Labels: managedBy}},
+			obj,
+		)
+		assert.NilError(t, UninstallZipkin(ctx, client, nil))
+		_, err = client.CoreV1().Namespaces().Get(ctx, KnToolsNamespace, metav1.GetOptions{})
+		assert.NilError(t, err)
+	}
+
+	// Only kn trace objects and the ones Kubernetes creates are left
+	owner := metav1.NewControllerRef(&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "zipkin-1234"}}, appsv1.SchemeGroupVersion.WithKind("ReplicaSet"))
+	client = fake.NewClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: KnToolsNamespace, Labels: managedBy}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "zipkin", Namespace: KnToolsNamespace, Labels: managedBy}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "zipkin-1234-abcd", Namespace: KnToolsNamespace, OwnerReferences: []metav1.OwnerReference{*owner}}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "kube-root-ca.crt", Namespace: KnToolsNamespace}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: KnToolsNamespace}},
+	)
+	assert.NilError(t, UninstallZipkin(ctx, client, nil))
+	_, err = client.CoreV1().Namespaces().Get(ctx, KnToolsNamespace, metav1.GetOptions{})
+	assert.Assert(t, apierrors.IsNotFound(err))
+
+	// A user-managed Zipkin is never removed
+	client = fake.NewClientset(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "zipkin", Namespace: KnToolsNamespace}})
+	assert.NilError(t, UninstallZipkin(ctx, client, nil))
+	_, err = client.AppsV1().Deployments(KnToolsNamespace).Get(ctx, "zipkin", metav1.GetOptions{})
+	assert.NilError(t, err)
+
+	// Zipkin still receives spans
+	used := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: config.ConfigMapName, Namespace: "knative-serving"},
+		Data:       map[string]string{"backend": "zipkin", "zipkin-endpoint": ZipkinEndpoint},
+	}
+	client = fake.NewClientset(used)
+	err = UninstallZipkin(ctx, client, []config.Target{{Component: config.Serving, Namespace: "knative-serving"}})
+	assert.ErrorContains(t, err, "still used")
+}
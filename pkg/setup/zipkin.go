@@ -16,6 +16,7 @@ package setup
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -24,16 +25,30 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
+	"knative.dev/kn-plugin-trace/pkg/config"
 
 	"knative.dev/client/pkg/kn/commands"
 )
 
 const (
 	KnToolsNamespace = "kntools"
+
+	// ZipkinEndpoint is the endpoint of the Zipkin installed by kn trace
+	ZipkinEndpoint = "http://zipkin." + KnToolsNamespace + ".svc.cluster.local:9411/api/v2/spans"
+
+	// ManagedByLabel marks the objects created by kn trace
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	ManagedBy      = "kn-trace"
 )
 
-// Zipkin sets up Zipkin and change tracing configuration accordingly
-func Zipkin(ctx context.Context, p *commands.KnParams) error {
+// restorable are the settings of a tracing ConfigMap restored when disabling tracing
+var restorable = []string{config.DebugKey, config.SampleRateKey}
+
+// Zipkin sets up Zipkin and changes the tracing configuration of the given
+// targets accordingly. All targets send spans to the same endpoint: the one of a
+// target already using Zipkin, else one left over by a previous configuration,
+// else the Zipkin installed by kn trace.
+func Zipkin(ctx context.Context, p *commands.KnParams, targets []config.Target) error {
 	cfg, err := p.RestConfig()
 	if err != nil {
 		return err
@@ -44,65 +59,131 @@ func Zipkin(ctx context.Context, p *commands.KnParams) error {
 		return err
 	}
 
-	// TODO: alternate eventing installation namespace
-	cm, err := client.CoreV1().ConfigMaps("knative-eventing").Get(ctx, "config-tracing", metav1.GetOptions{})
-	if err != nil {
-		if !apierrors.IsNotFound(err) {
-			return err
-		}
+	cms := make([]*corev1.ConfigMap, len(targets))
+	for i, target := range targets {
+		cm, err := client.CoreV1().ConfigMaps(target.Namespace).Get(ctx, config.ConfigMapName, metav1.GetOptions{})
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
 
-		// knative eventing hasn't been installed properly.
-		fmt.Println("⚠️ missing config-tracing in the knative-eventing namespace which is an indicator that Knative Eventing hasn't been properly installed. Recovering.")
-		cm = &corev1.ConfigMap{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: "config-tracing",
-			},
-			Data: map[string]string{},
+			// knative hasn't been installed properly.
+			fmt.Printf("⚠️ missing %s in the %s namespace which is an indicator that Knative %s hasn't been properly installed. Creating it.\n", config.ConfigMapName, target.Namespace, target.Component)
+			cm = &corev1.ConfigMap{}
 		}
-	}
 
-	updated := false
-
-	backend, ok := cm.Data["backend"]
-	if !ok {
-		cm.Data["backend"] = "zipkin"
-		updated = true
-	} else if backend != "zipkin" {
-		return fmt.Errorf("incompatible tracing configuration: unsupported %s backend", backend)
+		backend := cm.Data[config.BackendKey]
+		switch backend {
+		case "", "none", "zipkin":
+		default:
+			return fmt.Errorf("incompatible tracing configuration of %s: unsupported %s backend", target, backend)
+		}
+		cms[i] = cm
 	}
 
-	endpoint, ok := cm.Data["zipkin-endpoint"]
-	if !ok {
+	// The Zipkin installed by kn trace may have been uninstalled since
+	endpoint := chooseEndpoint(cms)
+	if endpoint == "" || endpoint == ZipkinEndpoint {
 		endpoint, err = installZipkin(ctx, client)
 		if err != nil {
 			return fmt.Errorf("failed to install Zipkin: %w", err)
 		}
+	}
 
-		cm.Data["zipkin-endpoint"] = endpoint
-		updated = true
-	} else {
+	for i, target := range targets {
 		// TODO: Check endpoint is a real zipkin
 		// opentelemetry support receiving zipkin span but does not support queries
+		if current, ok := cms[i].Data[config.ZipkinEndpointKey]; ok && current != endpoint {
+			fmt.Printf("⚠️ %s sent spans to %s, now sends them to %s\n", target, current, endpoint)
+		}
 
+		updated, err := config.Write(ctx, client, target, func(cm *corev1.ConfigMap) (bool, error) {
+			return enableZipkin(cm, endpoint)
+		})
+		if err != nil {
+			return err
+		}
+
+		if updated {
+			fmt.Printf("tracing configuration of %s successfully created\n", target)
+		} else {
+			fmt.Printf("tracing configuration of %s unchanged\n", target)
+		}
 	}
 
-	debug, ok := cm.Data["debug"]
+	return nil
+}
+
+// chooseEndpoint returns the Zipkin endpoint of the first ConfigMap using
+// Zipkin, else the first endpoint left over, else an empty string
+func chooseEndpoint(cms []*corev1.ConfigMap) string {
+	leftover := ""
+	for _, cm := range cms {
+		endpoint := cm.Data[config.ZipkinEndpointKey]
+		if endpoint == "" {
+			continue
+		}
+		if cm.Data[config.BackendKey] == "zipkin" {
+			return endpoint
+		}
+		if leftover == "" {
+			leftover = endpoint
+		}
+	}
+	return leftover
+}
+
+// enableZipkin sends the spans of the ConfigMap to the given Zipkin endpoint
+// and enables debug mode
+func enableZipkin(cm *corev1.ConfigMap, endpoint string) (bool, error) {
+	if err := recordPrevious(cm); err != nil {
+		return false, err
+	}
+
+	updated := false
+	if cm.Data[config.BackendKey] != "zipkin" {
+		cm.Data[config.BackendKey] = "zipkin"
+		updated = true
+	}
+
+	if cm.Data[config.ZipkinEndpointKey] != endpoint {
+		cm.Data[config.ZipkinEndpointKey] = endpoint
+		updated = true
+	}
+
+	debug, ok := cm.Data[config.DebugKey]
 	if !ok || debug != "true" {
-		cm.Data["debug"] = "true"
+		cm.Data[config.DebugKey] = "true"
 		updated = true
 	}
+	return updated, nil
+}
 
-	if updated {
-		_, err = client.CoreV1().ConfigMaps("knative-eventing").Update(ctx, cm, metav1.UpdateOptions{})
-		if err != nil {
-			return err
-		}
+// recordPrevious records the restorable settings of the ConfigMap unless
+// they have already been recorded by a previous enable
+func recordPrevious(cm *corev1.ConfigMap) error {
+	if cm.Data["backend"] == "zipkin" {
+		return nil
+	}
+	if _, ok := cm.Annotations[config.PreviousAnnotation]; ok {
+		return nil
+	}
 
-		fmt.Println("tracing configuration successfully created")
-	} else {
-		fmt.Println("tracing configuration unchanged")
+	previous := make(map[string]string)
+	for _, key := range restorable {
+		if value, ok := cm.Data[key]; ok {
+			previous[key] = value
+		}
 	}
 
+	data, err := json.Marshal(previous)
+	if err != nil {
+		return err
+	}
+	if cm.Annotations == nil {
+		cm.Annotations = map[string]string{}
+	}
+	cm.Annotations[config.PreviousAnnotation] = string(data)
 	return nil
 }
 
@@ -117,7 +198,8 @@ func installZipkin(ctx context.Context, client kubernetes.Interface) (string, er
 		}
 		ns := corev1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
-				Name: KnToolsNamespace,
+				Name:   KnToolsNamespace,
+				Labels: map[string]string{ManagedByLabel: ManagedBy},
 			},
 		}
 		_, err := client.CoreV1().Namespaces().Create(ctx, &ns, metav1.CreateOptions{})
@@ -137,11 +219,15 @@ func installZipkin(ctx context.Context, client kubernetes.Interface) (string, er
 		labels := map[string]string{
 			"app": "zipkin",
 		}
+		objectLabels := map[string]string{
+			"app":          "zipkin",
+			ManagedByLabel: ManagedBy,
+		}
 
 		d := appsv1.Deployment{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:   "zipkin",
-				Labels: labels,
+				Labels: objectLabels,
 			},
 			Spec: appsv1.DeploymentSpec{
 				Selector: &metav1.LabelSelector{
@@ -166,7 +252,7 @@ func installZipkin(ctx context.Context, client kubernetes.Interface) (string, er
 		s := corev1.Service{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:   "zipkin",
-				Labels: labels,
+				Labels: objectLabels,
 			},
 
 			Spec: corev1.ServiceSpec{
@@ -186,5 +272,5 @@ func installZipkin(ctx context.Context, client kubernetes.Interface) (string, er
 		// TODO: wait for endpoint to be ready
 	}
 
-	return "http://zipkin." + KnToolsNamespace + ".svc.cluster.local:9411/api/v2/spans", nil
+	return ZipkinEndpoint, nil
 }
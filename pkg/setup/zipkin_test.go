@@ -0,0 +1,38 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package setup
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestChooseEndpoint(t *testing.T) {
+	leftover := &corev1.ConfigMap{Data: map[string]string{"backend": "none", "zipkin-endpoint": "http://zipkin.tracing:9411/api/v2/spans"}}
+	zipkin := &corev1.ConfigMap{Data: map[string]string{"backend": "zipkin", "zipkin-endpoint": "http://zipkin.observability:9411/api/v2/spans"}}
+	missing := &corev1.ConfigMap{}
+
+	assert.Equal(t, chooseEndpoint([]*corev1.ConfigMap{leftover, zipkin}), "http://zipkin.observability:9411/api/v2/spans")
+	assert.Equal(t, chooseEndpoint([]*corev1.ConfigMap{missing, leftover}), "http://zipkin.tracing:9411/api/v2/spans")
+	assert.Equal(t, chooseEndpoint([]*corev1.ConfigMap{missing}), "")
+
+	// All targets are sent to the chosen endpoint
+	updated, err := enableZipkin(leftover, "http://zipkin.observability:9411/api/v2/spans")
+	assert.NilError(t, err)
+	assert.Assert(t, updated)
+	assert.Equal(t, leftover.Data["zipkin-endpoint"], "http://zipkin.observability:9411/api/v2/spans")
+}
@@ -0,0 +1,309 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tempo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"knative.dev/kn-plugin-trace/pkg/otel"
+	"knative.dev/kn-plugin-trace/pkg/proxy"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+// QueryPort is the port of the Tempo query API
+const QueryPort = 3200
+
+const (
+	// maxSearchWindow is the longest search window. Tempo rejects searches
+	// exceeding its max_duration, 168h by default.
+	maxSearchWindow = 168 * time.Hour
+
+	// defaultLimit is the number of traces returned by Tempo when the query has no limit
+	defaultLimit = 20
+
+	// traceTTL is how long a fetched trace is reused by the following searches,
+	// so that traces going through several services are fetched once per poll
+	traceTTL = 10 * time.Second
+
+	// traceCacheCapacity is the maximum number of fetched traces kept
+	traceCacheCapacity = 1000
+)
+
+var _ trace.Store = (*Connection)(nil)
+
+type Connection struct {
+	client proxy.Client
+
+	mu     sync.Mutex
+	traces map[string]cachedTrace
+}
+
+// cachedTrace is a trace fetched by a search
+type cachedTrace struct {
+	spans     []trace.Span
+	fetchedAt time.Time
+}
+
+// Connect connects to the Tempo instance receiving spans on the given Zipkin
+// endpoint, either directly or through an OpenTelemetry collector exporting
+// traces to Tempo. A non-empty exporter names the collector exporter sending
+// traces to Tempo.
+func Connect(ctx context.Context, endpoint string, restcfg *rest.Config, options proxy.Options, exporter string) (*Connection, error) {
+	if exporter == "" {
+		c, err := DirectConnect(ctx, endpoint, restcfg, options)
+		if err == nil {
+			return c, nil
+		}
+	}
+
+	endpoint, err := otel.ResolveTempo(ctx, endpoint, restcfg, exporter)
+	if err != nil {
+		return nil, err
+	}
+	return DirectConnect(ctx, endpoint, restcfg, options)
+}
+
+// DirectConnect connects to the query API of the given Tempo endpoint.
+//
+// In distributed mode, the query API of the "<name>-distributor" service
+// is served by "<name>-query-frontend".
+func DirectConnect(ctx context.Context, endpoint string, restcfg *rest.Config, options proxy.Options) (*Connection, error) {
+	// OTLP gRPC endpoints usually have no scheme
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+
+	url, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	parts := regexp.MustCompile("[.:]").Split(url.Host, -1)
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("malformed endpoint %q", endpoint)
+	}
+
+	svcName := parts[0]
+	if strings.HasSuffix(svcName, "-distributor") {
+		svcName = strings.TrimSuffix(svcName, "-distributor") + "-query-frontend"
+	}
+
+	client, err := proxy.NewClient(ctx, restcfg, fmt.Sprintf("%s:%d", svcName, QueryPort), parts[1], options.Transport)
+	if err != nil {
+		return nil, err
+	}
+
+	connection := &Connection{client: client, traces: make(map[string]cachedTrace)}
+
+	// Check if endpoint is reachable
+	_, err = connection.Services(ctx)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return connection, nil
+}
+
+// Close releases the connections to the Tempo query API
+func (c *Connection) Close() error {
+	return c.client.Close()
+}
+
+// Services returns the names of the services which reported spans
+func (c *Connection) Services(ctx context.Context) ([]string, error) {
+	var resp TagValuesResponse
+	if err := c.get(ctx, "api/search/tag/"+serviceNameAttribute+"/values", &resp); err != nil {
+		return nil, err
+	}
+	return resp.TagValues, nil
+}
+
+// Search returns the traces matching the query. TraceQL is used when
+// available, falling back to the tags search of older Tempo versions.
+// The search window is limited to the last 168 hours before its end.
+func (c *Connection) Search(ctx context.Context, query trace.Query) ([][]trace.Span, error) {
+	start, end := searchWindow(query, time.Now())
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+
+	values := url.Values{}
+	values.Set("start", strconv.FormatInt(start.Unix(), 10))
+	values.Set("end", strconv.FormatInt(end.Unix(), 10))
+	if query.MinDuration > 0 {
+		values.Set("minDuration", query.MinDuration.String())
+	}
+	values.Set("limit", strconv.Itoa(limit))
+
+	values.Set("q", traceQL(query))
+
+	var resp SearchResponse
+	if err := c.get(ctx, "api/search?"+values.Encode(), &resp); err != nil {
+		if !isTraceQLError(err) {
+			return nil, err
+		}
+
+		values.Del("q")
+		if tags := logfmt(query); tags != "" {
+			values.Set("tags", tags)
+		}
+
+		if err := c.get(ctx, "api/search?"+values.Encode(), &resp); err != nil {
+			return nil, err
+		}
+	}
+
+	// Search only returns trace metadata
+	now := time.Now()
+	traces := make([][]trace.Span, 0, len(resp.Traces))
+	for i, metadata := range resp.Traces {
+		if i == limit {
+			break
+		}
+
+		spans, err := c.cachedTrace(ctx, metadata.TraceID, now)
+		if err != nil {
+			return nil, err
+		}
+		traces = append(traces, spans)
+	}
+	return traces, nil
+}
+
+// cachedTrace returns the trace with the given ID, fetching it unless it has
+// been fetched recently
+func (c *Connection) cachedTrace(ctx context.Context, traceID string, now time.Time) ([]trace.Span, error) {
+	c.mu.Lock()
+	cached, ok := c.traces[traceID]
+	c.mu.Unlock()
+	if ok && now.Sub(cached.fetchedAt) < traceTTL {
+		return cached.spans, nil
+	}
+
+	spans, err := c.Trace(ctx, traceID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.traces) >= traceCacheCapacity {
+		for id, cached := range c.traces {
+			if now.Sub(cached.fetchedAt) >= traceTTL {
+				delete(c.traces, id)
+			}
+		}
+	}
+	if len(c.traces) < traceCacheCapacity {
+		c.traces[traceID] = cachedTrace{spans: spans, fetchedAt: now}
+	}
+	return spans, nil
+}
+
+// Trace returns all the spans of the trace with the given ID
+func (c *Connection) Trace(ctx context.Context, traceID string) ([]trace.Span, error) {
+	var resp TraceResponse
+	if err := c.get(ctx, "api/traces/"+url.PathEscape(traceID), &resp); err != nil {
+		return nil, err
+	}
+	return toSpans(resp)
+}
+
+// Dependencies returns the calls between services during the lookback period
+// before end. Tempo has no dependencies API so they are derived from the
+// traces found in the period.
+func (c *Connection) Dependencies(ctx context.Context, end time.Time, lookback time.Duration) ([]trace.Dependency, error) {
+	traces, err := c.Search(ctx, trace.Query{End: end, Lookback: lookback})
+	if err != nil {
+		return nil, err
+	}
+
+	var spans []trace.Span
+	for _, t := range traces {
+		spans = append(spans, t...)
+	}
+	return trace.DeriveDependencies(spans), nil
+}
+
+func (c *Connection) get(ctx context.Context, path string, v interface{}) error {
+	return proxy.GetJSON(ctx, c.client, path, v)
+}
+
+// searchWindow returns the bounds of the search window, limited to maxSearchWindow
+func searchWindow(query trace.Query, now time.Time) (time.Time, time.Time) {
+	start, end := query.Window(now)
+	if end.Sub(start) > maxSearchWindow {
+		start = end.Add(-maxSearchWindow)
+	}
+	return start, end
+}
+
+// isTraceQLError returns true when Tempo rejected the TraceQL query, which
+// happens with versions not supporting TraceQL
+func isTraceQLError(err error) bool {
+	var serr *proxy.StatusError
+	if !errors.As(err, &serr) || serr.StatusCode != http.StatusBadRequest {
+		return false
+	}
+	body := strings.ToLower(serr.Body)
+	return strings.Contains(body, "traceql") || strings.Contains(body, "parse error")
+}
+
+// traceQL returns the TraceQL expression selecting the traces matching the query
+func traceQL(query trace.Query) string {
+	var conditions []string
+	if query.ServiceName != "" {
+		conditions = append(conditions, "resource."+serviceNameAttribute+" = "+strconv.Quote(query.ServiceName))
+	}
+	for _, key := range sortedKeys(query.Tags) {
+		// Unscoped since Zipkin tags may end up in span or resource attributes
+		conditions = append(conditions, "."+key+" = "+strconv.Quote(query.Tags[key]))
+	}
+	return "{" + strings.Join(conditions, " && ") + "}"
+}
+
+// logfmt returns the tags search matching the query
+func logfmt(query trace.Query) string {
+	var terms []string
+	if query.ServiceName != "" {
+		terms = append(terms, serviceNameAttribute+"="+strconv.Quote(query.ServiceName))
+	}
+	for _, key := range sortedKeys(query.Tags) {
+		terms = append(terms, key+"="+strconv.Quote(query.Tags[key]))
+	}
+	return strings.Join(terms, " ")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
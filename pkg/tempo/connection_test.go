@@ -0,0 +1,96 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tempo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"knative.dev/kn-plugin-trace/pkg/proxy"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+func TestSearch(t *testing.T) {
+	traceQL := true
+	var searches, fetches int
+	var window time.Duration
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/search":
+			searches++
+			if r.URL.Query().Get("q") != "" && !traceQL {
+				http.Error(w, "invalid TraceQL query: parse error at line 1, col 1", http.StatusBadRequest)
+				return
+			}
+			start, _ := strconv.ParseInt(r.URL.Query().Get("start"), 10, 64)
+			end, _ := strconv.ParseInt(r.URL.Query().Get("end"), 10, 64)
+			window = time.Duration(end-start) * time.Second
+			w.Write([]byte(`{"traces": [{"traceID": "1"}]}`))
+		case "/api/traces/1":
+			fetches++
+			w.Write([]byte(traceJSON))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := proxy.NewHTTPClient(server.URL, proxy.HTTPOptions{})
+	assert.NilError(t, err)
+	connection := &Connection{client: client, traces: make(map[string]cachedTrace)}
+	ctx := context.Background()
+
+	// Searches without lookback are limited to the maximum window
+	traces, err := connection.Search(ctx, trace.Query{ServiceName: "a"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(traces), 1)
+	assert.Equal(t, window, maxSearchWindow)
+
+	// The trace found again through another service is not fetched again
+	_, err = connection.Search(ctx, trace.Query{ServiceName: "b"})
+	assert.NilError(t, err)
+	assert.Equal(t, fetches, 1)
+
+	// Tags search of versions without TraceQL
+	traceQL = false
+	searches = 0
+	traces, err = connection.Search(ctx, trace.Query{ServiceName: "a"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(traces), 1)
+	assert.Equal(t, searches, 2)
+}
+
+func TestSearchError(t *testing.T) {
+	var searches int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		searches++
+		http.Error(w, "range specified by start and end exceeds 168h0m0s", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client, err := proxy.NewHTTPClient(server.URL, proxy.HTTPOptions{})
+	assert.NilError(t, err)
+	connection := &Connection{client: client, traces: make(map[string]cachedTrace)}
+
+	// Only TraceQL errors fall back to the tags search
+	_, err = connection.Search(context.Background(), trace.Query{})
+	assert.ErrorContains(t, err, "exceeds")
+	assert.Equal(t, searches, 1)
+}
@@ -0,0 +1,207 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tempo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+const (
+	serviceNameAttribute = "service.name"
+	peerServiceAttribute = "peer.service"
+)
+
+// toSpans converts an OTLP trace to backend-agnostic spans
+func toSpans(t TraceResponse) ([]trace.Span, error) {
+	var spans []trace.Span
+	for _, rs := range append(t.Batches, t.ResourceSpans...) {
+		service := ""
+		for _, attr := range rs.Resource.Attributes {
+			if attr.Key == serviceNameAttribute {
+				service = attr.Value.String()
+			}
+		}
+
+		for _, ss := range append(rs.ScopeSpans, rs.InstrumentationLibrarySpans...) {
+			for _, s := range ss.Spans {
+				span, err := toSpan(s, service)
+				if err != nil {
+					return nil, err
+				}
+				spans = append(spans, span)
+			}
+		}
+	}
+	return spans, nil
+}
+
+func toSpan(s Span, service string) (trace.Span, error) {
+	traceID, err := parseTraceID(s.TraceID)
+	if err != nil {
+		return trace.Span{}, err
+	}
+
+	id, err := parseSpanID(s.SpanID)
+	if err != nil {
+		return trace.Span{}, err
+	}
+
+	start, err := parseUnixNano(s.StartTimeUnixNano)
+	if err != nil {
+		return trace.Span{}, err
+	}
+
+	end, err := parseUnixNano(s.EndTimeUnixNano)
+	if err != nil {
+		return trace.Span{}, err
+	}
+
+	span := trace.Span{
+		TraceID:   traceID,
+		ID:        id,
+		Name:      s.Name,
+		Kind:      toKind(s.Kind),
+		Timestamp: start,
+		Duration:  end.Sub(start),
+		Tags:      make(map[string]string, len(s.Attributes)),
+	}
+
+	if s.ParentSpanID != "" {
+		parentID, err := parseSpanID(s.ParentSpanID)
+		if err != nil {
+			return trace.Span{}, err
+		}
+		span.ParentID = parentID
+	}
+
+	if service != "" {
+		span.LocalEndpoint = &trace.Endpoint{ServiceName: service}
+	}
+
+	for _, attr := range s.Attributes {
+		value := attr.Value.String()
+		if attr.Key == peerServiceAttribute {
+			span.RemoteEndpoint = &trace.Endpoint{ServiceName: value}
+		}
+		span.Tags[attr.Key] = value
+	}
+
+	for _, event := range s.Events {
+		timestamp, err := parseUnixNano(event.TimeUnixNano)
+		if err != nil {
+			return trace.Span{}, err
+		}
+		span.Annotations = append(span.Annotations, trace.Annotation{Timestamp: timestamp, Value: event.Name})
+	}
+
+	if isError(s.Status.Code) {
+		msg := s.Status.Message
+		if msg == "" {
+			msg = "true"
+		}
+		span.Tags["error"] = msg
+	}
+
+	return span, nil
+}
+
+// String returns the value as a Zipkin tag value
+func (v AnyValue) String() string {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return strconv.FormatBool(*v.BoolValue)
+	case v.IntValue != "":
+		if i, err := v.IntValue.Int64(); err == nil {
+			return strconv.FormatInt(i, 10)
+		}
+		if f, err := v.IntValue.Float64(); err == nil {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		return v.IntValue.String()
+	case v.DoubleValue != nil:
+		return strconv.FormatFloat(*v.DoubleValue, 'g', -1, 64)
+	default:
+		return ""
+	}
+}
+
+func toKind(kind interface{}) trace.Kind {
+	switch kind {
+	case "SPAN_KIND_SERVER", float64(2):
+		return trace.KindServer
+	case "SPAN_KIND_CLIENT", float64(3):
+		return trace.KindClient
+	case "SPAN_KIND_PRODUCER", float64(4):
+		return trace.KindProducer
+	case "SPAN_KIND_CONSUMER", float64(5):
+		return trace.KindConsumer
+	default:
+		return trace.KindUndetermined
+	}
+}
+
+func isError(code interface{}) bool {
+	return code == "STATUS_CODE_ERROR" || code == float64(2)
+}
+
+// decodeID decodes hex (OTLP JSON) or base64 (Tempo) encoded IDs of the given size
+func decodeID(id string, size int) ([]byte, error) {
+	if b, err := hex.DecodeString(id); err == nil && len(b) == size {
+		return b, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(id)
+	if err != nil || len(b) != size {
+		return nil, fmt.Errorf("invalid ID %q", id)
+	}
+	return b, nil
+}
+
+// parseTraceID returns the hex encoding of the trace ID, without the
+// high 64 bits when they are all zeros
+func parseTraceID(id string) (string, error) {
+	b, err := decodeID(id, 16)
+	if err != nil {
+		return "", err
+	}
+	if bytes.Equal(b[:8], make([]byte, 8)) {
+		b = b[8:]
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func parseSpanID(id string) (string, error) {
+	b, err := decodeID(id, 8)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func parseUnixNano(ns string) (time.Time, error) {
+	v, err := strconv.ParseInt(ns, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q: %w", ns, err)
+	}
+	return time.Unix(0, v), nil
+}
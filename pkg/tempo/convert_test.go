@@ -0,0 +1,82 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tempo
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+const traceJSON = `{
+  "batches": [{
+    "resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "mt-broker-ingress"}}]},
+    "scopeSpans": [{
+      "spans": [{
+        "traceId": "AAAAAAAAAAEAAAAAAAAAAg==",
+        "spanId": "AAAAAAAAAAM=",
+        "parentSpanId": "0000000000000004",
+        "name": "broker:default.default",
+        "kind": "SPAN_KIND_SERVER",
+        "startTimeUnixNano": "1636020000000000000",
+        "endTimeUnixNano": "1636020000005000000",
+        "attributes": [
+          {"key": "cloudevents.id", "value": {"stringValue": "1234"}},
+          {"key": "http.status_code", "value": {"intValue": "500"}},
+          {"key": "messaging.message_payload_size_bytes", "value": {"intValue": 1000000}}
+        ],
+        "status": {"code": 2, "message": "internal error"}
+      }]
+    }]
+  }]
+}`
+
+func TestToSpanModels(t *testing.T) {
+	var resp TraceResponse
+	assert.NilError(t, json.Unmarshal([]byte(traceJSON), &resp))
+
+	spans, err := toSpans(resp)
+	assert.NilError(t, err)
+	assert.Equal(t, len(spans), 1)
+
+	span := spans[0]
+	assert.Equal(t, span.TraceID, "00000000000000010000000000000002")
+	assert.Equal(t, span.ID, "0000000000000003")
+	assert.Equal(t, span.ParentID, "0000000000000004")
+	assert.Equal(t, span.Kind, trace.KindServer)
+	assert.Equal(t, span.LocalEndpoint.ServiceName, "mt-broker-ingress")
+	assert.Equal(t, span.Timestamp, time.Unix(0, 1636020000000000000))
+	assert.Equal(t, span.Duration, 5*time.Millisecond)
+	assert.DeepEqual(t, span.Tags, map[string]string{
+		"cloudevents.id":                       "1234",
+		"http.status_code":                     "500",
+		"error":                                "internal error",
+		"messaging.message_payload_size_bytes": "1000000",
+	})
+}
+
+func TestTraceQL(t *testing.T) {
+	query := trace.Query{
+		ServiceName: "mt-broker-ingress",
+		Tags:        map[string]string{"cloudevents.type": "dev.knative.ping", "cloudevents.source": "/ping"},
+	}
+
+	assert.Equal(t, traceQL(query), `{resource.service.name = "mt-broker-ingress" && .cloudevents.source = "/ping" && .cloudevents.type = "dev.knative.ping"}`)
+	assert.Equal(t, logfmt(query), `service.name="mt-broker-ingress" cloudevents.source="/ping" cloudevents.type="dev.knative.ping"`)
+	assert.Equal(t, traceQL(trace.Query{}), "{}")
+}
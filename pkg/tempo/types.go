@@ -0,0 +1,92 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tempo
+
+import "encoding/json"
+
+// Types of the Tempo query HTTP API responses
+
+// TraceResponse is the OTLP JSON encoding of a trace, as returned by /api/traces/<id>.
+// Older Tempo versions use batches and instrumentationLibrarySpans.
+type TraceResponse struct {
+	Batches       []ResourceSpans `json:"batches"`
+	ResourceSpans []ResourceSpans `json:"resourceSpans"`
+}
+
+type ResourceSpans struct {
+	Resource                    Resource     `json:"resource"`
+	ScopeSpans                  []ScopeSpans `json:"scopeSpans"`
+	InstrumentationLibrarySpans []ScopeSpans `json:"instrumentationLibrarySpans"`
+}
+
+type Resource struct {
+	Attributes []KeyValue `json:"attributes"`
+}
+
+type ScopeSpans struct {
+	Spans []Span `json:"spans"`
+}
+
+type Span struct {
+	TraceID           string      `json:"traceId"`
+	SpanID            string      `json:"spanId"`
+	ParentSpanID      string      `json:"parentSpanId"`
+	Name              string      `json:"name"`
+	Kind              interface{} `json:"kind"` // enum name or number
+	StartTimeUnixNano string      `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string      `json:"endTimeUnixNano"`
+	Attributes        []KeyValue  `json:"attributes"`
+	Events            []Event     `json:"events"`
+	Status            Status      `json:"status"`
+}
+
+type KeyValue struct {
+	Key   string   `json:"key"`
+	Value AnyValue `json:"value"`
+}
+
+type AnyValue struct {
+	StringValue *string     `json:"stringValue,omitempty"`
+	BoolValue   *bool       `json:"boolValue,omitempty"`
+	IntValue    json.Number `json:"intValue,omitempty"` // int64 are encoded as strings
+	DoubleValue *float64    `json:"doubleValue,omitempty"`
+}
+
+type Event struct {
+	TimeUnixNano string     `json:"timeUnixNano"`
+	Name         string     `json:"name"`
+	Attributes   []KeyValue `json:"attributes"`
+}
+
+type Status struct {
+	Code    interface{} `json:"code"` // enum name or number
+	Message string      `json:"message"`
+}
+
+// SearchResponse is the response of /api/search
+type SearchResponse struct {
+	Traces []TraceMetadata `json:"traces"`
+}
+
+type TraceMetadata struct {
+	TraceID         string `json:"traceID"`
+	RootServiceName string `json:"rootServiceName"`
+	RootTraceName   string `json:"rootTraceName"`
+}
+
+// TagValuesResponse is the response of /api/search/tag/<tag>/values
+type TagValuesResponse struct {
+	TagValues []string `json:"tagValues"`
+}
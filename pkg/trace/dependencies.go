@@ -0,0 +1,100 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import "sort"
+
+// DeriveDependencies computes the calls between services from the given spans,
+// for backends without a dependencies API. Dependencies are sorted by parent
+// then child.
+func DeriveDependencies(spans []Span) []Dependency {
+	type link struct{ parent, child string }
+
+	counts := make(map[link]*Dependency)
+	count := func(parent, child string, failed bool) {
+		if parent == "" || child == "" || parent == child {
+			return
+		}
+		l := link{parent: parent, child: child}
+		dep, ok := counts[l]
+		if !ok {
+			dep = &Dependency{Parent: parent, Child: child}
+			counts[l] = dep
+		}
+		dep.CallCount++
+		if failed {
+			dep.ErrorCount++
+		}
+	}
+
+	for _, tree := range Build(spans) {
+		tree.Walk(func(node *Node, depth int) {
+			span := node.Span
+			called := false
+			for _, child := range node.Children {
+				if child.Span.Service() != span.Service() {
+					count(span.Service(), child.Span.Service(), child.Span.Failed())
+					called = true
+				}
+			}
+
+			// Calls to services which did not report spans
+			if !called && span.Kind == KindClient {
+				count(span.Service(), span.RemoteService(), span.Failed())
+			}
+		})
+	}
+
+	deps := make([]Dependency, 0, len(counts))
+	for _, dep := range counts {
+		deps = append(deps, *dep)
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].Parent != deps[j].Parent {
+			return deps[i].Parent < deps[j].Parent
+		}
+		return deps[i].Child < deps[j].Child
+	})
+	return deps
+}
+
+// Components guesses the role each service plays in the path of CloudEvents,
+// from the spans it reported. Services without a known role are omitted.
+func Components(spans []Span) map[string]string {
+	votes := make(map[string]map[string]int)
+	for _, span := range spans {
+		service := span.Service()
+		component := Component(span)
+		if service == "" || component == "" {
+			continue
+		}
+		if votes[service] == nil {
+			votes[service] = make(map[string]int)
+		}
+		votes[service][component]++
+	}
+
+	components := make(map[string]string, len(votes))
+	for service, counts := range votes {
+		best := ""
+		for component, count := range counts {
+			if count > counts[best] || (count == counts[best] && component < best) {
+				best = component
+			}
+		}
+		components[service] = best
+	}
+	return components
+}
@@ -0,0 +1,61 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestDeriveDependencies(t *testing.T) {
+	ingress := span(1, 1, 0, 0, 10*time.Millisecond)
+	ingress.LocalEndpoint = &Endpoint{ServiceName: "broker-ingress"}
+
+	filter := span(1, 2, 1, time.Millisecond, 5*time.Millisecond)
+	filter.LocalEndpoint = &Endpoint{ServiceName: "broker-filter"}
+
+	// Call to a subscriber without tracing
+	call := span(1, 3, 2, 2*time.Millisecond, time.Millisecond)
+	call.Kind = KindClient
+	call.LocalEndpoint = &Endpoint{ServiceName: "broker-filter"}
+	call.RemoteEndpoint = &Endpoint{ServiceName: "event-display"}
+	call.Tags = map[string]string{ErrorTag: "503"}
+
+	deps := DeriveDependencies([]Span{ingress, filter, call})
+	assert.DeepEqual(t, deps, []Dependency{
+		{Parent: "broker-filter", Child: "event-display", CallCount: 1, ErrorCount: 1},
+		{Parent: "broker-ingress", Child: "broker-filter", CallCount: 1},
+	})
+}
+
+func TestComponents(t *testing.T) {
+	ingress := span(1, 1, 0, 0, time.Millisecond)
+	ingress.Name = "broker:default"
+	ingress.LocalEndpoint = &Endpoint{ServiceName: "broker-ingress.knative-eventing"}
+
+	display := span(1, 2, 1, 0, time.Millisecond)
+	display.Kind = KindServer
+	display.LocalEndpoint = &Endpoint{ServiceName: "event-display"}
+
+	unknown := span(1, 3, 2, 0, time.Millisecond)
+	unknown.LocalEndpoint = &Endpoint{ServiceName: "db"}
+
+	assert.DeepEqual(t, Components([]Span{ingress, display, unknown}), map[string]string{
+		"broker-ingress.knative-eventing": ComponentBroker,
+		"event-display":                   ComponentSubscriber,
+	})
+}
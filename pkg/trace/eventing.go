@@ -0,0 +1,71 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import "strings"
+
+// Tags set by Knative Eventing on the spans of CloudEvents deliveries
+const (
+	CloudEventIDTag          = "cloudevents.id"
+	CloudEventSourceTag      = "cloudevents.source"
+	CloudEventTypeTag        = "cloudevents.type"
+	CloudEventSpecVersionTag = "cloudevents.specversion"
+	CloudEventSubjectTag     = "cloudevents.subject"
+
+	MessagingDestinationTag = "messaging.destination"
+)
+
+// Roles a span can play in the path of a CloudEvent
+const (
+	ComponentSource     = "source"
+	ComponentBroker     = "broker"
+	ComponentTrigger    = "trigger"
+	ComponentChannel    = "channel"
+	ComponentSubscriber = "subscriber"
+)
+
+// HasCloudEventID returns true when the span is tagged with the given CloudEvent ID
+func HasCloudEventID(span Span, id string) bool {
+	value, ok := span.Tags[CloudEventIDTag]
+	return ok && value == id
+}
+
+// Component guesses the role the span plays in the path of a CloudEvent.
+// It returns an empty string when the role cannot be determined.
+func Component(span Span) string {
+	destination := span.Tags[MessagingDestinationTag]
+	switch {
+	case strings.HasPrefix(span.Name, "broker:") || strings.HasPrefix(destination, "broker:"):
+		return ComponentBroker
+	case strings.HasPrefix(span.Name, "trigger:") || strings.HasPrefix(destination, "trigger:"):
+		return ComponentTrigger
+	}
+
+	service := span.Service()
+
+	switch {
+	case strings.Contains(service, "broker-ingress"):
+		return ComponentBroker
+	case strings.Contains(service, "broker-filter"):
+		return ComponentTrigger
+	case strings.Contains(service, "dispatcher") || strings.Contains(service, "channel"):
+		return ComponentChannel
+	case strings.Contains(service, "adapter") || strings.Contains(service, "source"):
+		return ComponentSource
+	case span.Kind == KindServer:
+		return ComponentSubscriber
+	}
+	return ""
+}
@@ -0,0 +1,170 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fake provides an in-memory trace store for testing
+package fake
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+var _ trace.Store = (*Store)(nil)
+
+// Store is an in-memory trace store
+type Store struct {
+	spans []trace.Span
+
+	// Err is returned by all the queries when set
+	Err error
+
+	// Queries records the searches made against the store
+	Queries []trace.Query
+}
+
+// NewStore creates a store holding the given spans
+func NewStore(spans ...trace.Span) *Store {
+	return &Store{spans: spans}
+}
+
+// Add adds spans to the store
+func (s *Store) Add(spans ...trace.Span) {
+	s.spans = append(s.spans, spans...)
+}
+
+// Services returns the names of the services which reported spans
+func (s *Store) Services(ctx context.Context) ([]string, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+
+	seen := make(map[string]bool)
+	var services []string
+	for _, span := range s.spans {
+		if service := span.Service(); service != "" && !seen[service] {
+			seen[service] = true
+			services = append(services, service)
+		}
+	}
+	sort.Strings(services)
+	return services, nil
+}
+
+// Search returns the traces matching the query, oldest first
+func (s *Store) Search(ctx context.Context, query trace.Query) ([][]trace.Span, error) {
+	s.Queries = append(s.Queries, query)
+	if s.Err != nil {
+		return nil, s.Err
+	}
+
+	start, end := query.Window(time.Now())
+
+	var traces [][]trace.Span
+	for _, tree := range trace.Build(s.spans) {
+		spans := s.traceSpans(tree.TraceID)
+		if !matches(query, tree, spans, start, end) {
+			continue
+		}
+		traces = append(traces, spans)
+		if query.Limit > 0 && len(traces) == query.Limit {
+			break
+		}
+	}
+	return traces, nil
+}
+
+// Trace returns all the spans of the trace with the given ID
+func (s *Store) Trace(ctx context.Context, traceID string) ([]trace.Span, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+
+	spans := s.traceSpans(traceID)
+	if len(spans) == 0 {
+		return nil, fmt.Errorf("trace %s not found", traceID)
+	}
+	return spans, nil
+}
+
+// Dependencies returns the calls between services, derived from the spans
+// started during the lookback period before end
+func (s *Store) Dependencies(ctx context.Context, end time.Time, lookback time.Duration) ([]trace.Dependency, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+
+	start, end := trace.Query{End: end, Lookback: lookback}.Window(time.Now())
+
+	var spans []trace.Span
+	for _, span := range s.spans {
+		if !span.Timestamp.Before(start) && !span.Timestamp.After(end) {
+			spans = append(spans, span)
+		}
+	}
+	return trace.DeriveDependencies(spans), nil
+}
+
+func (s *Store) traceSpans(traceID string) []trace.Span {
+	var spans []trace.Span
+	for _, span := range s.spans {
+		if span.TraceID == traceID {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// matches applies the Zipkin search semantics: each criteria must be
+// satisfied by at least one span of the trace.
+func matches(query trace.Query, tree *trace.Tree, spans []trace.Span, start, end time.Time) bool {
+	if tree.Start.Before(start) || tree.Start.After(end) {
+		return false
+	}
+
+	if tree.Duration < query.MinDuration {
+		return false
+	}
+
+	if query.ServiceName != "" && !anySpan(spans, func(span trace.Span) bool { return span.Service() == query.ServiceName }) {
+		return false
+	}
+
+	for key, value := range query.Tags {
+		if !anySpan(spans, func(span trace.Span) bool {
+			v, ok := span.Tags[key]
+			return ok && v == value
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+func anySpan(spans []trace.Span, fn func(span trace.Span) bool) bool {
+	for _, span := range spans {
+		if fn(span) {
+			return true
+		}
+	}
+	return false
+}
+
+// Close does nothing
+func (s *Store) Close() error {
+	return nil
+}
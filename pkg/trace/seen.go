@@ -0,0 +1,68 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import "container/list"
+
+// SpanKey uniquely identifies a span
+type SpanKey struct {
+	TraceID string
+	ID      string
+	Shared  bool
+}
+
+// KeyOf returns the key identifying the given span
+func KeyOf(span Span) SpanKey {
+	return SpanKey{TraceID: span.TraceID, ID: span.ID, Shared: span.Shared}
+}
+
+// Seen remembers a bounded number of spans. When full, the least recently
+// seen span is forgotten.
+type Seen struct {
+	capacity int
+	entries  map[SpanKey]*list.Element
+	order    *list.List // most recently seen first
+}
+
+// NewSeen creates a set remembering up to capacity spans
+func NewSeen(capacity int) *Seen {
+	return &Seen{
+		capacity: capacity,
+		entries:  make(map[SpanKey]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Add records the span and returns true when it has not been seen before
+func (s *Seen) Add(span Span) bool {
+	key := KeyOf(span)
+	if elem, ok := s.entries[key]; ok {
+		s.order.MoveToFront(elem)
+		return false
+	}
+
+	s.entries[key] = s.order.PushFront(key)
+	for s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(SpanKey))
+	}
+	return true
+}
+
+// Len returns the number of spans currently remembered
+func (s *Seen) Len() int {
+	return s.order.Len()
+}
@@ -0,0 +1,43 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestSeen(t *testing.T) {
+	seen := NewSeen(2)
+
+	a := span(1, 1, 0, 0, 0)
+	b := span(1, 2, 1, 0, 0)
+	c := span(2, 1, 0, 0, 0)
+
+	assert.Assert(t, seen.Add(a))
+	assert.Assert(t, !seen.Add(a))
+	assert.Assert(t, seen.Add(b))
+
+	// a is more recent than b
+	assert.Assert(t, !seen.Add(a))
+
+	// c evicts b
+	assert.Assert(t, seen.Add(c))
+	assert.Equal(t, seen.Len(), 2)
+	assert.Assert(t, !seen.Add(a))
+	assert.Assert(t, !seen.Add(c))
+	assert.Assert(t, seen.Add(b))
+}
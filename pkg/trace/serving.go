@@ -0,0 +1,53 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import "strings"
+
+// HTTP tags set by Knative Serving on the spans of requests
+const (
+	HTTPHostTag       = "http.host"
+	HTTPMethodTag     = "http.method"
+	HTTPPathTag       = "http.path"
+	HTTPURLTag        = "http.url"
+	HTTPStatusCodeTag = "http.status_code"
+)
+
+// ActivatorService is the service name of the spans reported by the Knative Serving activator
+const ActivatorService = "activator-service"
+
+// deploymentInfix separates the revision name from the rest of the pod name
+// reported by the queue-proxy as service name
+const deploymentInfix = "-deployment-"
+
+// IsServingRequest returns true when the span is a request received by the
+// activator or the queue-proxy of a Knative Service
+func IsServingRequest(span Span) bool {
+	if span.Kind != KindServer || span.Tags[HTTPHostTag] == "" {
+		return false
+	}
+	service := span.Service()
+	return service == ActivatorService || strings.Contains(service, deploymentInfix)
+}
+
+// Revision returns the name of the revision which served the request, or an
+// empty string when the span was not reported by a queue-proxy
+func Revision(span Span) string {
+	service := span.Service()
+	if i := strings.Index(service, deploymentInfix); i > 0 {
+		return service[:i]
+	}
+	return ""
+}
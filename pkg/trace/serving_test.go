@@ -0,0 +1,40 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestServingRequest(t *testing.T) {
+	tags := map[string]string{HTTPHostTag: "hello.default.svc.cluster.local"}
+
+	queue := Span{Kind: KindServer, Tags: tags, LocalEndpoint: &Endpoint{ServiceName: "hello-00001-deployment-5d8f9c7b6d-x2x4z"}}
+	assert.Assert(t, IsServingRequest(queue))
+	assert.Equal(t, Revision(queue), "hello-00001")
+
+	activator := Span{Kind: KindServer, Tags: tags, LocalEndpoint: &Endpoint{ServiceName: ActivatorService}}
+	assert.Assert(t, IsServingRequest(activator))
+	assert.Equal(t, Revision(activator), "")
+
+	// Outgoing call of the queue-proxy
+	client := Span{Kind: KindClient, Tags: tags, LocalEndpoint: queue.LocalEndpoint}
+	assert.Assert(t, !IsServingRequest(client))
+
+	broker := Span{Kind: KindServer, Tags: tags, LocalEndpoint: &Endpoint{ServiceName: "broker-ingress.knative-eventing"}}
+	assert.Assert(t, !IsServingRequest(broker))
+}
@@ -0,0 +1,111 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import "time"
+
+// Kind is the role of a span in a remote call
+type Kind string
+
+// Span kinds. Local spans have no kind.
+const (
+	KindUndetermined Kind = ""
+	KindClient       Kind = "CLIENT"
+	KindServer       Kind = "SERVER"
+	KindProducer     Kind = "PRODUCER"
+	KindConsumer     Kind = "CONSUMER"
+)
+
+// ErrorTag is the tag set on failed spans
+const ErrorTag = "error"
+
+// Span is a timed operation, independent of the backend storing it
+type Span struct {
+	// TraceID is the hex-encoded ID of the trace the span belongs to
+	TraceID string
+
+	// ID is the hex-encoded ID of the span
+	ID string
+
+	// ParentID is the ID of the parent span, empty for root spans
+	ParentID string
+
+	Name string
+	Kind Kind
+
+	// Shared is true for server spans sharing their ID with the client span
+	Shared bool
+
+	Timestamp time.Time
+	Duration  time.Duration
+
+	LocalEndpoint  *Endpoint
+	RemoteEndpoint *Endpoint
+
+	Annotations []Annotation
+	Tags        map[string]string
+
+	// Resource is the Knative object the span belongs to, nil when unknown.
+	// It is not reported by backends but resolved from the cluster.
+	Resource *Resource
+}
+
+// Endpoint is the network context of a span
+type Endpoint struct {
+	ServiceName string
+	IPv4        string
+	IPv6        string
+	Port        uint16
+}
+
+// Resource identifies a Kubernetes object
+type Resource struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// String returns the resource as Kind/namespace/name
+func (r Resource) String() string {
+	return r.Kind + "/" + r.Namespace + "/" + r.Name
+}
+
+// Annotation is an event that occurred during a span
+type Annotation struct {
+	Timestamp time.Time
+	Value     string
+}
+
+// Service returns the name of the service which reported the span
+func (s Span) Service() string {
+	if s.LocalEndpoint == nil {
+		return ""
+	}
+	return s.LocalEndpoint.ServiceName
+}
+
+// RemoteService returns the name of the service on the other side of the span
+func (s Span) RemoteService() string {
+	if s.RemoteEndpoint == nil {
+		return ""
+	}
+	return s.RemoteEndpoint.ServiceName
+}
+
+// Failed returns true when the span is tagged as an error
+func (s Span) Failed() bool {
+	_, ok := s.Tags[ErrorTag]
+	return ok
+}
@@ -0,0 +1,82 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import (
+	"context"
+	"time"
+)
+
+// Store queries traces from a tracing backend
+type Store interface {
+	// Services returns the names of the services which reported spans
+	Services(ctx context.Context) ([]string, error)
+
+	// Search returns the traces matching the query
+	Search(ctx context.Context, query Query) ([][]Span, error)
+
+	// Trace returns all the spans of the trace with the given ID
+	Trace(ctx context.Context, traceID string) ([]Span, error)
+
+	// Dependencies returns the calls between services during the lookback
+	// period before end
+	Dependencies(ctx context.Context, end time.Time, lookback time.Duration) ([]Dependency, error)
+
+	// Close releases the connections to the backend
+	Close() error
+}
+
+// Dependency aggregates the calls from a parent service to a child service
+type Dependency struct {
+	Parent     string
+	Child      string
+	CallCount  int64
+	ErrorCount int64
+}
+
+// Query holds the parameters of a trace search
+type Query struct {
+	// ServiceName restricts the search to the traces going through this service (optional)
+	ServiceName string
+
+	// Tags restricts the search to the traces with spans having all these tags (optional)
+	Tags map[string]string
+
+	// MinDuration restricts the search to the traces lasting at least this duration (optional)
+	MinDuration time.Duration
+
+	// End is the upper bound of the search window. Default to now.
+	End time.Time
+
+	// Lookback is the size of the search window. Default to all traces before End.
+	Lookback time.Duration
+
+	// Limit is the maximum number of traces to return (optional)
+	Limit int
+}
+
+// Window returns the bounds of the search window, using now for the default end
+func (q Query) Window(now time.Time) (start time.Time, end time.Time) {
+	end = q.End
+	if end.IsZero() {
+		end = now
+	}
+
+	start = time.UnixMilli(0)
+	if q.Lookback > 0 {
+		start = end.Add(-q.Lookback)
+	}
+	return start, end
+}
@@ -0,0 +1,163 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import (
+	"sort"
+	"time"
+)
+
+// Tree is a trace assembled from its spans
+type Tree struct {
+	TraceID string
+
+	// Roots are the spans without a parent in the trace.
+	// Spans whose parent has not been reported are also roots.
+	Roots []*Node
+
+	// Start is the timestamp of the earliest span in the trace
+	Start time.Time
+
+	// Duration is the time elapsed between the start of the earliest span
+	// and the end of the latest one
+	Duration time.Duration
+}
+
+// Node is a span within a trace tree
+type Node struct {
+	Span     Span
+	Children []*Node
+}
+
+// Offset returns the time elapsed between the start of the trace and the start of the span
+func (t *Tree) Offset(span Span) time.Duration {
+	if span.Timestamp.IsZero() || t.Start.IsZero() {
+		return 0
+	}
+	return span.Timestamp.Sub(t.Start)
+}
+
+// Walk visits all the nodes of the tree, depth first, in start order
+func (t *Tree) Walk(fn func(node *Node, depth int)) {
+	for _, root := range t.Roots {
+		root.walk(fn, 0)
+	}
+}
+
+func (n *Node) walk(fn func(node *Node, depth int), depth int) {
+	fn(n, depth)
+	for _, child := range n.Children {
+		child.walk(fn, depth+1)
+	}
+}
+
+// Build groups the given spans by trace ID and assembles them into trees.
+// Duplicated spans are ignored. Trees are ordered by start time.
+func Build(spans []Span) []*Tree {
+	byTrace := make(map[string][]Span)
+	var order []string
+	for _, span := range spans {
+		if _, ok := byTrace[span.TraceID]; !ok {
+			order = append(order, span.TraceID)
+		}
+		byTrace[span.TraceID] = append(byTrace[span.TraceID], span)
+	}
+
+	trees := make([]*Tree, 0, len(order))
+	for _, traceID := range order {
+		trees = append(trees, buildTree(traceID, byTrace[traceID]))
+	}
+
+	sort.SliceStable(trees, func(i, j int) bool {
+		return trees[i].Start.Before(trees[j].Start)
+	})
+	return trees
+}
+
+// nodeKey identifies a span within a trace. Zipkin allows a server span to
+// share its ID with the client span that caused it.
+type nodeKey struct {
+	id     string
+	shared bool
+}
+
+func buildTree(traceID string, spans []Span) *Tree {
+	tree := &Tree{TraceID: traceID}
+
+	nodes := make(map[nodeKey]*Node, len(spans))
+	var ordered []*Node
+	for _, span := range spans {
+		key := nodeKey{id: span.ID, shared: span.Shared}
+		if _, ok := nodes[key]; ok {
+			continue
+		}
+		node := &Node{Span: span}
+		nodes[key] = node
+		ordered = append(ordered, node)
+	}
+
+	var end time.Time
+	for _, node := range ordered {
+		span := node.Span
+		if !span.Timestamp.IsZero() {
+			if tree.Start.IsZero() || span.Timestamp.Before(tree.Start) {
+				tree.Start = span.Timestamp
+			}
+			if spanEnd := span.Timestamp.Add(span.Duration); spanEnd.After(end) {
+				end = spanEnd
+			}
+		}
+
+		if parent := parentOf(nodes, span); parent != nil && parent != node {
+			parent.Children = append(parent.Children, node)
+		} else {
+			tree.Roots = append(tree.Roots, node)
+		}
+	}
+
+	if !tree.Start.IsZero() {
+		tree.Duration = end.Sub(tree.Start)
+	}
+
+	sortNodes(tree.Roots)
+	return tree
+}
+
+func parentOf(nodes map[nodeKey]*Node, span Span) *Node {
+	// A shared span is the server side of the client span with the same ID
+	if span.Shared {
+		if parent, ok := nodes[nodeKey{id: span.ID}]; ok {
+			return parent
+		}
+	}
+
+	if span.ParentID == "" {
+		return nil
+	}
+
+	if parent, ok := nodes[nodeKey{id: span.ParentID, shared: true}]; ok {
+		return parent
+	}
+	return nodes[nodeKey{id: span.ParentID}]
+}
+
+func sortNodes(nodes []*Node) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].Span.Timestamp.Before(nodes[j].Span.Timestamp)
+	})
+	for _, node := range nodes {
+		sortNodes(node.Children)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+var start = time.Unix(1636000000, 0)
+
+func hexID(id uint64) string {
+	return fmt.Sprintf("%016x", id)
+}
+
+func span(traceID, id, parent uint64, offset, duration time.Duration) Span {
+	s := Span{
+		TraceID:   hexID(traceID),
+		ID:        hexID(id),
+		Timestamp: start.Add(offset),
+		Duration:  duration,
+	}
+	if parent != 0 {
+		s.ParentID = hexID(parent)
+	}
+	return s
+}
+
+func TestBuild(t *testing.T) {
+	spans := []Span{
+		span(1, 3, 1, 5*time.Millisecond, 2*time.Millisecond),
+		span(1, 1, 0, 0, 10*time.Millisecond),
+		span(2, 10, 0, time.Second, time.Millisecond),
+		span(1, 2, 1, 1*time.Millisecond, 12*time.Millisecond),
+		span(1, 2, 1, 1*time.Millisecond, 12*time.Millisecond), // duplicate
+		span(1, 4, 99, 20*time.Millisecond, time.Millisecond),  // missing parent
+	}
+
+	trees := Build(spans)
+	assert.Equal(t, len(trees), 2)
+
+	tree := trees[0]
+	assert.Equal(t, tree.TraceID, hexID(1))
+	assert.Equal(t, tree.Start, start)
+	assert.Equal(t, tree.Duration, 21*time.Millisecond)
+
+	assert.Equal(t, len(tree.Roots), 2)
+	assert.Equal(t, tree.Roots[0].Span.ID, hexID(1))
+	assert.Equal(t, tree.Roots[1].Span.ID, hexID(4))
+
+	children := tree.Roots[0].Children
+	assert.Equal(t, len(children), 2)
+	assert.Equal(t, children[0].Span.ID, hexID(2))
+	assert.Equal(t, children[1].Span.ID, hexID(3))
+	assert.Equal(t, tree.Offset(children[1].Span), 5*time.Millisecond)
+
+	assert.Equal(t, trees[1].TraceID, hexID(2))
+}
+
+func TestBuildSharedSpan(t *testing.T) {
+	client := span(1, 1, 0, 0, 10*time.Millisecond)
+	server := span(1, 1, 0, time.Millisecond, 8*time.Millisecond)
+	server.Shared = true
+	child := span(1, 2, 1, 2*time.Millisecond, time.Millisecond)
+
+	trees := Build([]Span{client, server, child})
+	assert.Equal(t, len(trees), 1)
+	assert.Equal(t, len(trees[0].Roots), 1)
+
+	root := trees[0].Roots[0]
+	assert.Equal(t, root.Span.Shared, false)
+	assert.Equal(t, len(root.Children), 1)
+	assert.Equal(t, root.Children[0].Span.Shared, true)
+	assert.Equal(t, root.Children[0].Children[0].Span.ID, hexID(2))
+}
@@ -0,0 +1,233 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zipkin
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/openzipkin/zipkin-go/model"
+	"knative.dev/kn-plugin-trace/pkg/proxy"
+)
+
+// Client wraps the Zipkin v2 query API, as described in https://zipkin.io/zipkin-api/
+type Client struct {
+	client proxy.Client
+}
+
+// NewClient creates a client for the Zipkin instance reachable through the given client
+func NewClient(client proxy.Client) *Client {
+	return &Client{client: client}
+}
+
+// Close releases the connections to Zipkin
+func (c *Client) Close() error {
+	return c.client.Close()
+}
+
+// SpansQuery holds the parameters of a span names lookup
+type SpansQuery struct {
+	// ServiceName is the service which reported the spans (required)
+	ServiceName string
+
+	// SpanKind restricts the lookup to spans of this kind (optional)
+	SpanKind model.Kind
+}
+
+func (q SpansQuery) encode() string {
+	values := url.Values{}
+	values.Set("serviceName", q.ServiceName)
+	if q.SpanKind != model.Undetermined {
+		values.Set("spanKind", string(q.SpanKind))
+	}
+	return values.Encode()
+}
+
+// TracesQuery holds the parameters of a trace search
+type TracesQuery struct {
+	// ServiceName restricts the search to the traces going through this service (optional)
+	ServiceName string
+
+	// RemoteServiceName restricts the search to the traces calling this remote service (optional)
+	RemoteServiceName string
+
+	// SpanName restricts the search to the traces with a span of this name (optional)
+	SpanName string
+
+	// AnnotationQuery restricts the search to the traces with matching tags or annotations (optional).
+	// For instance "cloudevents.id=1234 and error"
+	AnnotationQuery string
+
+	// MinDuration restricts the search to the traces lasting at least this many microseconds (optional)
+	MinDuration int64
+
+	// MaxDuration restricts the search to the traces lasting at most this many microseconds (optional)
+	MaxDuration int64
+
+	// EndTs is the upper bound of the search window, in milliseconds since epoch
+	EndTs int64
+
+	// Lookback is the size of the search window, in milliseconds
+	Lookback int64
+
+	// Limit is the maximum number of traces to return
+	Limit int
+}
+
+func (q TracesQuery) encode() string {
+	values := url.Values{}
+	if q.ServiceName != "" {
+		values.Set("serviceName", q.ServiceName)
+	}
+	if q.RemoteServiceName != "" {
+		values.Set("remoteServiceName", q.RemoteServiceName)
+	}
+	if q.SpanName != "" {
+		values.Set("spanName", q.SpanName)
+	}
+	if q.AnnotationQuery != "" {
+		values.Set("annotationQuery", q.AnnotationQuery)
+	}
+	if q.MinDuration > 0 {
+		values.Set("minDuration", strconv.FormatInt(q.MinDuration, 10))
+	}
+	if q.MaxDuration > 0 {
+		values.Set("maxDuration", strconv.FormatInt(q.MaxDuration, 10))
+	}
+	if q.EndTs > 0 {
+		values.Set("endTs", strconv.FormatInt(q.EndTs, 10))
+	}
+	if q.Lookback > 0 {
+		values.Set("lookback", strconv.FormatInt(q.Lookback, 10))
+	}
+	if q.Limit > 0 {
+		values.Set("limit", strconv.Itoa(q.Limit))
+	}
+	return values.Encode()
+}
+
+// DependenciesQuery holds the parameters of a dependency links lookup
+type DependenciesQuery struct {
+	// EndTs is the upper bound of the window, in milliseconds since epoch (required)
+	EndTs int64
+
+	// Lookback is the size of the window, in milliseconds. Default to a day.
+	Lookback int64
+}
+
+func (q DependenciesQuery) encode() string {
+	values := url.Values{}
+	values.Set("endTs", strconv.FormatInt(q.EndTs, 10))
+	if q.Lookback > 0 {
+		values.Set("lookback", strconv.FormatInt(q.Lookback, 10))
+	}
+	return values.Encode()
+}
+
+// Services returns the names of the services which reported spans
+func (c *Client) Services(ctx context.Context) ([]string, error) {
+	var services ServicesResponse
+	if err := c.get(ctx, "api/v2/services", &services); err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+// RemoteServices returns the names of the services called by the given service
+func (c *Client) RemoteServices(ctx context.Context, serviceName string) ([]string, error) {
+	values := url.Values{}
+	values.Set("serviceName", serviceName)
+
+	var services []string
+	if err := c.get(ctx, "api/v2/remoteServices?"+values.Encode(), &services); err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+// Spans returns the names of the spans reported by a service
+func (c *Client) Spans(ctx context.Context, query SpansQuery) ([]string, error) {
+	var names []string
+	if err := c.get(ctx, "api/v2/spans?"+query.encode(), &names); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// Traces returns the traces matching the given query
+func (c *Client) Traces(ctx context.Context, query TracesQuery) ([][]model.SpanModel, error) {
+	var traces [][]model.SpanModel
+	if err := c.get(ctx, "api/v2/traces?"+query.encode(), &traces); err != nil {
+		return nil, err
+	}
+	return traces, nil
+}
+
+// Trace returns the spans of the trace with the given ID
+func (c *Client) Trace(ctx context.Context, traceID string) ([]model.SpanModel, error) {
+	var spans []model.SpanModel
+	if err := c.get(ctx, "api/v2/trace/"+url.PathEscape(traceID), &spans); err != nil {
+		return nil, err
+	}
+	return spans, nil
+}
+
+// TraceMany returns the traces with the given IDs. Traces which are not found are omitted.
+func (c *Client) TraceMany(ctx context.Context, traceIDs []string) ([][]model.SpanModel, error) {
+	values := url.Values{}
+	values.Set("traceIds", strings.Join(traceIDs, ","))
+
+	var traces [][]model.SpanModel
+	if err := c.get(ctx, "api/v2/traceMany?"+values.Encode(), &traces); err != nil {
+		return nil, err
+	}
+	return traces, nil
+}
+
+// Dependencies returns the aggregated calls between services
+func (c *Client) Dependencies(ctx context.Context, query DependenciesQuery) ([]DependencyLink, error) {
+	var links []DependencyLink
+	if err := c.get(ctx, "api/v2/dependencies?"+query.encode(), &links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// AutocompleteKeys returns the tag keys which can be autocompleted
+func (c *Client) AutocompleteKeys(ctx context.Context) ([]string, error) {
+	var keys []string
+	if err := c.get(ctx, "api/v2/autocompleteKeys", &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// AutocompleteValues returns the values recorded for the given tag key
+func (c *Client) AutocompleteValues(ctx context.Context, key string) ([]string, error) {
+	values := url.Values{}
+	values.Set("key", key)
+
+	var result []string
+	if err := c.get(ctx, "api/v2/autocompleteValues?"+values.Encode(), &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, v interface{}) error {
+	return proxy.GetJSON(ctx, c.client, path, v)
+}
@@ -0,0 +1,81 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zipkin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openzipkin/zipkin-go/model"
+	"gotest.tools/v3/assert"
+	"knative.dev/kn-plugin-trace/pkg/proxy"
+)
+
+func TestClientRequests(t *testing.T) {
+	var uri string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uri = r.URL.RequestURI()
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	httpClient, err := proxy.NewHTTPClient(server.URL, proxy.HTTPOptions{})
+	assert.NilError(t, err)
+	client := NewClient(httpClient)
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		call func() error
+		uri  string
+	}{{
+		call: func() error { _, err := client.Services(ctx); return err },
+		uri:  "/api/v2/services",
+	}, {
+		call: func() error { _, err := client.RemoteServices(ctx, "broker ingress"); return err },
+		uri:  "/api/v2/remoteServices?serviceName=broker+ingress",
+	}, {
+		call: func() error {
+			_, err := client.Spans(ctx, SpansQuery{ServiceName: "a&b", SpanKind: model.Server})
+			return err
+		},
+		uri: "/api/v2/spans?serviceName=a%26b&spanKind=SERVER",
+	}, {
+		call: func() error {
+			_, err := client.Traces(ctx, TracesQuery{ServiceName: "svc", AnnotationQuery: "error and cloudevents.id=1", EndTs: 2000, Lookback: 1000, Limit: 10})
+			return err
+		},
+		uri: "/api/v2/traces?annotationQuery=error+and+cloudevents.id%3D1&endTs=2000&limit=10&lookback=1000&serviceName=svc",
+	}, {
+		call: func() error { _, err := client.Trace(ctx, "463ac35c9f6413ad"); return err },
+		uri:  "/api/v2/trace/463ac35c9f6413ad",
+	}, {
+		call: func() error { _, err := client.TraceMany(ctx, []string{"1", "2"}); return err },
+		uri:  "/api/v2/traceMany?traceIds=1%2C2",
+	}, {
+		call: func() error { _, err := client.Dependencies(ctx, DependenciesQuery{EndTs: 2000}); return err },
+		uri:  "/api/v2/dependencies?endTs=2000",
+	}, {
+		call: func() error { _, err := client.AutocompleteKeys(ctx); return err },
+		uri:  "/api/v2/autocompleteKeys",
+	}, {
+		call: func() error { _, err := client.AutocompleteValues(ctx, "http.path"); return err },
+		uri:  "/api/v2/autocompleteValues?key=http.path",
+	}} {
+		assert.NilError(t, tc.call())
+		assert.Equal(t, uri, tc.uri)
+	}
+}
@@ -16,39 +16,53 @@ package zipkin
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/url"
 	"regexp"
+	"sort"
+	"strings"
+	"time"
 
-	"github.com/openzipkin/zipkin-go/model"
 	"k8s.io/client-go/rest"
 	"knative.dev/kn-plugin-trace/pkg/otel"
 	"knative.dev/kn-plugin-trace/pkg/proxy"
+	"knative.dev/kn-plugin-trace/pkg/trace"
 )
 
+var _ trace.Store = (*Connection)(nil)
+
 type Connection struct {
+	// external is true when Zipkin is reached without going through the cluster
 	external bool
-	proxy    proxy.Proxy
-
-	svcName      string
-	svcNamespace string
+	api      *Client
 }
 
-func Connect(ctx context.Context, endpoint string, restcfg *rest.Config) (*Connection, error) {
-	if c, err := DirectConnect(endpoint, restcfg); err == nil {
+// Connect connects to the Zipkin instance receiving spans on the given endpoint,
+// either directly or through an OpenTelemetry collector exporting traces to Zipkin.
+func Connect(ctx context.Context, endpoint string, restcfg *rest.Config, options proxy.Options) (*Connection, error) {
+	c, err := DirectConnect(ctx, endpoint, restcfg, options)
+	if err == nil {
 		return c, nil
 	}
+	if !isClusterLocal(endpoint) {
+		return nil, err
+	}
 
 	// Try connecting via OpenTelemetry
-	endpoint, err := otel.ResolveZipkin(ctx, endpoint, restcfg)
+	endpoint, err = otel.ResolveZipkin(ctx, endpoint, restcfg)
 	if err != nil {
 		return nil, err
 	}
-	return DirectConnect(endpoint, restcfg)
+	return DirectConnect(ctx, endpoint, restcfg, options)
 }
 
-func DirectConnect(endpoint string, restcfg *rest.Config) (*Connection, error) {
+// DirectConnect connects to the Zipkin instance at the given endpoint. Cluster-local
+// endpoints are reached through the Kubernetes API server, others over HTTP(S).
+func DirectConnect(ctx context.Context, endpoint string, restcfg *rest.Config, options proxy.Options) (*Connection, error) {
+	if !isClusterLocal(endpoint) {
+		return ExternalConnect(ctx, endpoint, options.HTTP)
+	}
+
 	url, err := url.Parse(endpoint)
 	if err != nil {
 		return nil, err
@@ -58,57 +72,117 @@ func DirectConnect(endpoint string, restcfg *rest.Config) (*Connection, error) {
 		return nil, fmt.Errorf("malformed endpoint %q", endpoint)
 	}
 
-	// TODO: detect whether zipkin is externally accessible, in which case there is no need to create a proxy
-
-	proxy, err := proxy.New(restcfg)
+	client, err := proxy.NewClient(ctx, restcfg, parts[0], parts[1], options.Transport)
 	if err != nil {
 		return nil, err
 	}
 
-	connection := Connection{
-		external:     false, // TODO.
-		proxy:        proxy,
-		svcName:      parts[0],
-		svcNamespace: parts[1],
+	return connect(ctx, Connection{api: NewClient(client)})
+}
+
+// ExternalConnect connects over HTTP(S) to a Zipkin instance reachable from
+// outside the cluster. The endpoint is either the Zipkin root URL or its spans endpoint.
+func ExternalConnect(ctx context.Context, endpoint string, options proxy.HTTPOptions) (*Connection, error) {
+	base := strings.TrimSuffix(strings.TrimSuffix(endpoint, "/"), "/api/v2/spans")
+
+	client, err := proxy.NewHTTPClient(base, options)
+	if err != nil {
+		return nil, err
 	}
 
+	return connect(ctx, Connection{external: true, api: NewClient(client)})
+}
+
+func connect(ctx context.Context, connection Connection) (*Connection, error) {
 	// Check if endpoint is reachable
-	_, err = connection.Services()
+	_, err := connection.Services(ctx)
 	if err != nil {
+		connection.Close()
 		return nil, err
 	}
 
 	return &connection, nil
 }
 
-func (c *Connection) Services() (ServicesResponse, error) {
-	resp, err := c.proxy.Get(c.svcName, c.svcNamespace, "api/v2/services")
+// isClusterLocal returns true when the endpoint host is only reachable from within the cluster.
+// Malformed endpoints are reported when connecting.
+func isClusterLocal(endpoint string) bool {
+	url, err := url.Parse(endpoint)
 	if err != nil {
-		return nil, err
+		return true
+	}
+	return proxy.IsClusterLocal(url.Host)
+}
+
+// Client returns the client of the Zipkin query API
+func (c *Connection) Client() *Client {
+	return c.api
+}
+
+// Close releases the connections to Zipkin
+func (c *Connection) Close() error {
+	return c.api.Close()
+}
+
+// Services returns the names of the services which reported spans
+func (c *Connection) Services(ctx context.Context) ([]string, error) {
+	return c.api.Services(ctx)
+}
+
+// Search returns the traces matching the query
+func (c *Connection) Search(ctx context.Context, query trace.Query) ([][]trace.Span, error) {
+	start, end := query.Window(time.Now())
+
+	keys := make([]string, 0, len(query.Tags))
+	for key := range query.Tags {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
 
-	var services ServicesResponse
-	err = json.Unmarshal([]byte(resp), &services)
+	terms := make([]string, 0, len(keys))
+	for _, key := range keys {
+		terms = append(terms, key+"="+query.Tags[key])
+	}
+
+	traces, err := c.api.Traces(ctx, TracesQuery{
+		ServiceName:     query.ServiceName,
+		AnnotationQuery: strings.Join(terms, " and "),
+		MinDuration:     query.MinDuration.Microseconds(),
+		EndTs:           end.UnixMilli(),
+		Lookback:        end.Sub(start).Milliseconds(),
+		Limit:           query.Limit,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return services, nil
+	result := make([][]trace.Span, 0, len(traces))
+	for _, t := range traces {
+		result = append(result, ToSpans(t))
+	}
+	return result, nil
 }
 
-func (c *Connection) Spans(serviceName string, endTs, lookback int64) ([][]model.SpanModel, error) {
-	traces, err := c.proxy.Get(c.svcName, c.svcNamespace, fmt.Sprintf("api/v2/traces?serviceName=%s&lookback=%d&endTs=%d&limit=200", serviceName, lookback, endTs))
-
+// Trace returns all the spans of the trace with the given ID
+func (c *Connection) Trace(ctx context.Context, traceID string) ([]trace.Span, error) {
+	spans, err := c.api.Trace(ctx, traceID)
 	if err != nil {
 		return nil, err
 	}
 
-	var spans [][]model.SpanModel
-	err = json.Unmarshal([]byte(traces), &spans)
+	return ToSpans(spans), nil
+}
+
+// Dependencies returns the calls between services during the lookback period before end
+func (c *Connection) Dependencies(ctx context.Context, end time.Time, lookback time.Duration) ([]trace.Dependency, error) {
+	links, err := c.api.Dependencies(ctx, DependenciesQuery{EndTs: end.UnixMilli(), Lookback: lookback.Milliseconds()})
 	if err != nil {
 		return nil, err
 	}
 
-	return spans, nil
-
+	deps := make([]trace.Dependency, 0, len(links))
+	for _, link := range links {
+		deps = append(deps, trace.Dependency{Parent: link.Parent, Child: link.Child, CallCount: link.CallCount, ErrorCount: link.ErrorCount})
+	}
+	return deps, nil
 }
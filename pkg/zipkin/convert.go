@@ -0,0 +1,69 @@
+// Copyright © 2021 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zipkin
+
+import (
+	"github.com/openzipkin/zipkin-go/model"
+	"knative.dev/kn-plugin-trace/pkg/trace"
+)
+
+// ToSpans converts Zipkin spans to backend-agnostic spans
+func ToSpans(spans []model.SpanModel) []trace.Span {
+	out := make([]trace.Span, 0, len(spans))
+	for _, span := range spans {
+		out = append(out, ToSpan(span))
+	}
+	return out
+}
+
+// ToSpan converts a Zipkin span to a backend-agnostic span
+func ToSpan(span model.SpanModel) trace.Span {
+	s := trace.Span{
+		TraceID:        span.TraceID.String(),
+		ID:             span.ID.String(),
+		Name:           span.Name,
+		Kind:           trace.Kind(span.Kind),
+		Shared:         span.Shared,
+		Timestamp:      span.Timestamp,
+		Duration:       span.Duration,
+		LocalEndpoint:  toEndpoint(span.LocalEndpoint),
+		RemoteEndpoint: toEndpoint(span.RemoteEndpoint),
+		Tags:           span.Tags,
+	}
+
+	if span.ParentID != nil {
+		s.ParentID = span.ParentID.String()
+	}
+
+	for _, annotation := range span.Annotations {
+		s.Annotations = append(s.Annotations, trace.Annotation{Timestamp: annotation.Timestamp, Value: annotation.Value})
+	}
+	return s
+}
+
+func toEndpoint(endpoint *model.Endpoint) *trace.Endpoint {
+	if endpoint == nil {
+		return nil
+	}
+
+	e := &trace.Endpoint{ServiceName: endpoint.ServiceName, Port: endpoint.Port}
+	if endpoint.IPv4 != nil {
+		e.IPv4 = endpoint.IPv4.String()
+	}
+	if endpoint.IPv6 != nil {
+		e.IPv6 = endpoint.IPv6.String()
+	}
+	return e
+}
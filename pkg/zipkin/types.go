@@ -15,3 +15,11 @@
 package zipkin
 
 type ServicesResponse []string
+
+// DependencyLink is an aggregate of the calls from a parent service to a child service
+type DependencyLink struct {
+	Parent     string `json:"parent"`
+	Child      string `json:"child"`
+	CallCount  int64  `json:"callCount"`
+	ErrorCount int64  `json:"errorCount,omitempty"`
+}